@@ -0,0 +1,121 @@
+package httpwr
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// ErrRangeNotSatisfiable is returned by Attachment for a range outside of the content.
+var ErrRangeNotSatisfiable = errors.New("range not satisfiable")
+
+// Attachment sends content as a file attachment named filename, so browsers download it.
+// The Content-Type is guessed from the extension of filename.
+//
+// A request with a single byte range in the Range header, like `bytes=100-199`,
+// gets the partial content with http.StatusPartialContent, so downloads can be resumed.
+// A range outside of the content returns ErrRangeNotSatisfiable, wrapped with
+// http.StatusRequestedRangeNotSatisfiable, before anything is written.
+// A request without a Range header, or with several ranges, gets the whole content.
+func Attachment(w http.ResponseWriter, r *http.Request, filename string, content io.ReadSeeker) error {
+	disposition := mime.FormatMediaType("attachment", map[string]string{"filename": filename})
+	if disposition == "" {
+		return fmt.Errorf("httpwr: invalid attachment filename %q", filename)
+	}
+
+	size, err := content.Seek(0, io.SeekEnd)
+	if err != nil {
+		return err
+	}
+
+	start, length, status := int64(0), size, http.StatusOK
+	if header := r.Header.Get("Range"); header != "" {
+		var ok bool
+		start, length, ok, err = parseRange(header, size)
+		if err != nil {
+			return WrapWithHeader(http.StatusRequestedRangeNotSatisfiable, err, http.Header{
+				"Content-Range": {fmt.Sprintf("bytes */%d", size)},
+			})
+		}
+		if ok {
+			status = http.StatusPartialContent
+		} else {
+			start, length = 0, size
+		}
+	}
+
+	if _, err := content.Seek(start, io.SeekStart); err != nil {
+		return err
+	}
+
+	contentType := mime.TypeByExtension(filepath.Ext(filename))
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+
+	h := w.Header()
+	h.Set("Content-Disposition", disposition)
+	h.Set("Content-Type", contentType)
+	h.Set("Accept-Ranges", "bytes")
+	h.Set("Content-Length", strconv.FormatInt(length, 10))
+	if status == http.StatusPartialContent {
+		h.Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", start, start+length-1, size))
+	}
+	w.WriteHeader(status)
+
+	if r.Method == http.MethodHead {
+		return nil
+	}
+
+	_, err = io.CopyN(w, content, length)
+	return err
+}
+
+// parseRange parses a Range header with a single byte range for a content of size bytes.
+// ok is false if the range should be ignored, like a range with several parts or an other unit.
+func parseRange(header string, size int64) (start, length int64, ok bool, err error) {
+	spec, found := strings.CutPrefix(header, "bytes=")
+	if !found || strings.Contains(spec, ",") {
+		return 0, 0, false, nil
+	}
+
+	first, last, found := strings.Cut(strings.TrimSpace(spec), "-")
+	if !found {
+		return 0, 0, false, ErrRangeNotSatisfiable
+	}
+
+	if first == "" {
+		// A suffix range, like bytes=-500 for the last 500 bytes.
+		n, err := strconv.ParseInt(last, 10, 64)
+		if err != nil || n <= 0 || size == 0 {
+			return 0, 0, false, ErrRangeNotSatisfiable
+		}
+		if n > size {
+			n = size
+		}
+		return size - n, n, true, nil
+	}
+
+	start, err = strconv.ParseInt(first, 10, 64)
+	if err != nil || start < 0 || start >= size {
+		return 0, 0, false, ErrRangeNotSatisfiable
+	}
+
+	end := size - 1
+	if last != "" {
+		end, err = strconv.ParseInt(last, 10, 64)
+		if err != nil || end < start {
+			return 0, 0, false, ErrRangeNotSatisfiable
+		}
+		if end >= size {
+			end = size - 1
+		}
+	}
+
+	return start, end - start + 1, true, nil
+}
@@ -0,0 +1,70 @@
+package httpwr
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestAttachment(t *testing.T) {
+	const content = "0123456789"
+
+	h := F(func(w http.ResponseWriter, r *http.Request) error {
+		return Attachment(w, r, "digits.txt", strings.NewReader(content))
+	})
+
+	tests := []struct {
+		name         string
+		rng          string
+		wantStatus   int
+		contentRange string
+		body         string
+	}{
+		{name: "full", wantStatus: http.StatusOK, body: content},
+		{name: "valid range", rng: "bytes=2-5", wantStatus: http.StatusPartialContent, contentRange: "bytes 2-5/10", body: "2345"},
+		{name: "open ended range", rng: "bytes=7-", wantStatus: http.StatusPartialContent, contentRange: "bytes 7-9/10", body: "789"},
+		{name: "suffix range", rng: "bytes=-3", wantStatus: http.StatusPartialContent, contentRange: "bytes 7-9/10", body: "789"},
+		{name: "out of bounds range", rng: "bytes=20-30", wantStatus: http.StatusRequestedRangeNotSatisfiable, contentRange: "bytes */10"},
+		{name: "several ranges", rng: "bytes=0-1,4-5", wantStatus: http.StatusOK, body: content},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/download", nil)
+			if tt.rng != "" {
+				req.Header.Set("Range", tt.rng)
+			}
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("expected http status %d, got %d", tt.wantStatus, w.Code)
+			}
+			if got := w.Header().Get("Content-Range"); got != tt.contentRange {
+				t.Fatalf("expected Content-Range %q, got %q", tt.contentRange, got)
+			}
+			if tt.wantStatus != http.StatusRequestedRangeNotSatisfiable && w.Body.String() != tt.body {
+				t.Fatalf("expected body %q, got %q", tt.body, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestAttachmentHeaders(t *testing.T) {
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/download", nil)
+	if err := Attachment(w, req, "report.json", strings.NewReader(`{}`)); err != nil {
+		t.Fatalf("got error: %v", err)
+	}
+
+	if got := w.Header().Get("Content-Disposition"); got != "attachment; filename=report.json" {
+		t.Fatalf("unexpected Content-Disposition %q", got)
+	}
+	if got := w.Header().Get("Content-Type"); got != "application/json" {
+		t.Fatalf("unexpected Content-Type %q", got)
+	}
+	if got := w.Header().Get("Accept-Ranges"); got != "bytes" {
+		t.Fatalf("unexpected Accept-Ranges %q", got)
+	}
+}
@@ -0,0 +1,28 @@
+package httpwr
+
+import (
+	"net/http"
+	"strconv"
+)
+
+// BasicAuth protects the next handler with HTTP basic authentication.
+// The credentials from the Authorization header are checked with verify,
+// which should compare them with crypto/subtle.ConstantTimeCompare
+// to not leak them through timing.
+// Missing or wrong credentials are rejected with http.StatusUnauthorized
+// and a WWW-Authenticate header asking for the given realm.
+func BasicAuth(realm string, verify func(user, pass string) bool) Middleware {
+	challenge := http.Header{"Www-Authenticate": {"Basic realm=" + strconv.Quote(realm)}}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			user, pass, ok := r.BasicAuth()
+			if !ok || !verify(user, pass) {
+				handleError(w, WrapWithHeader(http.StatusUnauthorized, ErrUnauthorized, challenge), DefaultErrorHandler)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
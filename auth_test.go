@@ -0,0 +1,55 @@
+package httpwr
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestBasicAuth(t *testing.T) {
+	verify := func(user, pass string) bool {
+		userOK := subtle.ConstantTimeCompare([]byte(user), []byte("admin")) == 1
+		passOK := subtle.ConstantTimeCompare([]byte(pass), []byte("secret")) == 1
+		return userOK && passOK
+	}
+
+	h := BasicAuth("admin area", verify)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	tests := []struct {
+		name       string
+		user, pass string
+		setAuth    bool
+		wantStatus int
+	}{
+		{name: "missing credentials", wantStatus: http.StatusUnauthorized},
+		{name: "wrong credentials", user: "admin", pass: "wrong", setAuth: true, wantStatus: http.StatusUnauthorized},
+		{name: "correct credentials", user: "admin", pass: "secret", setAuth: true, wantStatus: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/", nil)
+			if tt.setAuth {
+				req.SetBasicAuth(tt.user, tt.pass)
+			}
+
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("expected http status %d, got %d", tt.wantStatus, w.Code)
+			}
+
+			challenge := w.Header().Get("WWW-Authenticate")
+			if tt.wantStatus == http.StatusUnauthorized && challenge != `Basic realm="admin area"` {
+				t.Fatalf("unexpected WWW-Authenticate header %q", challenge)
+			}
+			if tt.wantStatus == http.StatusOK && challenge != "" {
+				t.Fatalf("unexpected WWW-Authenticate header %q", challenge)
+			}
+		})
+	}
+}
@@ -0,0 +1,160 @@
+package httpwr
+
+import (
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// MaxMultipartMemory is the maximum number of bytes of a multipart form
+// that are stored in memory, the rest is stored on disk in temporary files.
+var MaxMultipartMemory int64 = 32 << 20
+
+// BindQuery populates the fields of v from the URL query parameters.
+// Fields are matched using the `query:"name"` tag, fields without the tag are ignored.
+// Supported field types are string, bool, integers, floats and slices of them.
+// Values that can not be parsed are wrapped with http.StatusBadRequest.
+func BindQuery[T any](r *http.Request, v *T) error {
+	return bindValues(r.URL.Query(), v, "query")
+}
+
+// BindForm populates the fields of v from the form values of the request.
+// Both application/x-www-form-urlencoded and multipart/form-data bodies are supported.
+// Fields are matched using the `form:"name"` tag, fields without the tag are ignored.
+// Parse and conversion failures are wrapped with http.StatusBadRequest.
+func BindForm[T any](r *http.Request, v *T) error {
+	return bindForm(r, v)
+}
+
+func bindForm(r *http.Request, v any) error {
+	if err := parseForm(r); err != nil {
+		return Wrap(http.StatusBadRequest, err)
+	}
+
+	return bindValues(r.Form, v, "form")
+}
+
+func parseForm(r *http.Request) error {
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType == "multipart/form-data" {
+		return r.ParseMultipartForm(MaxMultipartMemory)
+	}
+
+	return r.ParseForm()
+}
+
+// Bind is the typed version of BindAny, it decodes the request body into v
+// based on the Content-Type header: JSON, urlencoded or multipart form.
+// Other media types are rejected with http.StatusUnsupportedMediaType,
+// and malformed bodies with http.StatusBadRequest.
+func Bind[T any](r *http.Request, v *T) error {
+	return BindAny(r, v)
+}
+
+// BindAny decodes the request body into v based on the Content-Type header.
+// JSON bodies are decoded like Decode, and form bodies are bound like BindForm.
+// Other media types are rejected with http.StatusUnsupportedMediaType.
+func BindAny(r *http.Request, v any) error {
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+
+	switch {
+	case isJSONMediaType(mediaType):
+		return decodeJSON(r, v, DecodeOptions{})
+	case mediaType == "application/x-www-form-urlencoded", mediaType == "multipart/form-data":
+		return bindForm(r, v)
+	default:
+		return Errorf(http.StatusUnsupportedMediaType, "unsupported media type %q", mediaType)
+	}
+}
+
+// isJSONMediaType reports whether mediaType is application/json
+// or a structured syntax suffix like application/vnd.api+json.
+func isJSONMediaType(mediaType string) bool {
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
+
+// bindValues sets the struct fields of v tagged with tag from values.
+func bindValues(values url.Values, v any, tag string) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return errors.New("httpwr: bind target must be a non-nil pointer to struct")
+	}
+
+	rv = rv.Elem()
+	rt := rv.Type()
+
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+		name := field.Tag.Get(tag)
+		if name == "" || name == "-" || !field.IsExported() {
+			continue
+		}
+
+		vals, ok := values[name]
+		if !ok || len(vals) == 0 {
+			continue
+		}
+
+		if err := setField(rv.Field(i), vals); err != nil {
+			return Wrap(http.StatusBadRequest, fmt.Errorf("invalid value for %s %q: %w", tag, name, err))
+		}
+	}
+
+	return nil
+}
+
+func setField(field reflect.Value, vals []string) error {
+	if field.Kind() != reflect.Slice {
+		return setValue(field, vals[0])
+	}
+
+	slice := reflect.MakeSlice(field.Type(), len(vals), len(vals))
+	for i, val := range vals {
+		if err := setValue(slice.Index(i), val); err != nil {
+			return err
+		}
+	}
+
+	field.Set(slice)
+	return nil
+}
+
+func setValue(field reflect.Value, val string) error {
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(val)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(val)
+		if err != nil {
+			return err
+		}
+		field.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(val, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(val, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(val, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetFloat(n)
+	default:
+		return fmt.Errorf("unsupported field type %s", field.Type())
+	}
+
+	return nil
+}
@@ -0,0 +1,211 @@
+package httpwr
+
+import (
+	"bytes"
+	"errors"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type bindTarget struct {
+	Name   string   `query:"name"`
+	Age    int      `query:"age"`
+	Active bool     `query:"active"`
+	Tags   []string `query:"tag"`
+	Skip   string
+}
+
+func TestBindQuery(t *testing.T) {
+	req := httptest.NewRequest("GET", "/search?name=sam&age=23&active=true&tag=a&tag=b&Skip=x", nil)
+
+	var got bindTarget
+	if err := BindQuery(req, &got); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	want := bindTarget{
+		Name:   "sam",
+		Age:    23,
+		Active: true,
+		Tags:   []string{"a", "b"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %+v, got %+v", want, got)
+	}
+}
+
+func TestBindQueryMalformedInt(t *testing.T) {
+	req := httptest.NewRequest("GET", "/search?age=abc", nil)
+
+	var got bindTarget
+	err := BindQuery(req, &got)
+
+	var herr Error
+	if !errors.As(err, &herr) {
+		t.Fatalf("expected Error, got %v", err)
+	}
+	if herr.Status != http.StatusBadRequest {
+		t.Fatalf("expected http status %d, got %d", http.StatusBadRequest, herr.Status)
+	}
+}
+
+type formTarget struct {
+	Name string `form:"name"`
+	Age  int    `form:"age"`
+}
+
+func TestBindFormURLEncoded(t *testing.T) {
+	body := url.Values{"name": {"sam"}, "age": {"23"}}.Encode()
+	req := httptest.NewRequest("POST", "/form", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	var got formTarget
+	if err := BindForm(req, &got); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if got.Name != "sam" || got.Age != 23 {
+		t.Fatalf("unexpected bound value: %+v", got)
+	}
+}
+
+func TestBindFormMultipart(t *testing.T) {
+	var buf bytes.Buffer
+	mw := multipart.NewWriter(&buf)
+	_ = mw.WriteField("name", "sam")
+	_ = mw.WriteField("age", "23")
+	_ = mw.Close()
+
+	req := httptest.NewRequest("POST", "/form", &buf)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+
+	var got formTarget
+	if err := BindForm(req, &got); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if got.Name != "sam" || got.Age != 23 {
+		t.Fatalf("unexpected bound value: %+v", got)
+	}
+}
+
+func TestBindFormMalformedMultipart(t *testing.T) {
+	req := httptest.NewRequest("POST", "/form", strings.NewReader("garbage"))
+	req.Header.Set("Content-Type", "multipart/form-data; boundary=nope")
+
+	var got formTarget
+	err := BindForm(req, &got)
+
+	var herr Error
+	if !errors.As(err, &herr) || herr.Status != http.StatusBadRequest {
+		t.Fatalf("expected bad request Error, got %v", err)
+	}
+}
+
+func TestBindAny(t *testing.T) {
+	type user struct {
+		Name string `json:"name" form:"name"`
+		Age  int    `json:"age" form:"age"`
+	}
+
+	var got user
+	h := F(func(w http.ResponseWriter, r *http.Request) error {
+		got = user{}
+		return BindAny(r, &got)
+	})
+
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		wantStatus  int
+	}{
+		{
+			name:        "json",
+			contentType: "application/json; charset=utf-8",
+			body:        `{"name":"sam","age":23}`,
+			wantStatus:  http.StatusOK,
+		},
+		{
+			name:        "form",
+			contentType: "application/x-www-form-urlencoded",
+			body:        "name=sam&age=23",
+			wantStatus:  http.StatusOK,
+		},
+		{
+			name:        "unsupported",
+			contentType: "text/yaml",
+			body:        "name: sam",
+			wantStatus:  http.StatusUnsupportedMediaType,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/bind", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", tt.contentType)
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("expected http status %d, got %d", tt.wantStatus, w.Code)
+			}
+			if tt.wantStatus == http.StatusOK && (got.Name != "sam" || got.Age != 23) {
+				t.Fatalf("unexpected bound value: %+v", got)
+			}
+		})
+	}
+}
+
+func TestBind(t *testing.T) {
+	type user struct {
+		Name string `json:"name" form:"name"`
+		Age  int    `json:"age" form:"age"`
+	}
+
+	var multipartBody bytes.Buffer
+	mw := multipart.NewWriter(&multipartBody)
+	_ = mw.WriteField("name", "sam")
+	_ = mw.WriteField("age", "23")
+	_ = mw.Close()
+
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		wantStatus  int
+	}{
+		{name: "json", contentType: "application/json", body: `{"name":"sam","age":23}`, wantStatus: http.StatusOK},
+		{name: "form", contentType: "application/x-www-form-urlencoded", body: "name=sam&age=23", wantStatus: http.StatusOK},
+		{name: "multipart", contentType: mw.FormDataContentType(), body: multipartBody.String(), wantStatus: http.StatusOK},
+		{name: "malformed json", contentType: "application/json", body: `{"name":`, wantStatus: http.StatusBadRequest},
+		{name: "unsupported", contentType: "text/yaml", body: "name: sam", wantStatus: http.StatusUnsupportedMediaType},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/bind", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", tt.contentType)
+
+			var got user
+			err := Bind(req, &got)
+
+			if tt.wantStatus == http.StatusOK {
+				if err != nil {
+					t.Fatalf("expected no error, got %v", err)
+				}
+				if got != (user{Name: "sam", Age: 23}) {
+					t.Fatalf("unexpected bound value: %+v", got)
+				}
+				return
+			}
+
+			var herr Error
+			if !errors.As(err, &herr) || herr.Status != tt.wantStatus {
+				t.Fatalf("expected Error with http status %d, got %v", tt.wantStatus, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,156 @@
+package httpwr
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// JSONWithETag converts data to JSON and sends it with a strong ETag computed from the body.
+// If the request is a GET or HEAD whose If-None-Match header matches the ETag,
+// http.StatusNotModified is sent without a body instead.
+func JSONWithETag(w http.ResponseWriter, r *http.Request, status int, data any) error {
+	buf := getJSONBuffer()
+	defer putJSONBuffer(buf)
+
+	if err := buf.encode(data); err != nil {
+		return err
+	}
+	body := buf.Bytes()
+
+	sum := sha256.Sum256(body)
+	etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+	w.Header().Set("ETag", etag)
+
+	if isConditional(r, status) && etagMatch(r.Header.Get("If-None-Match"), etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return nil
+	}
+
+	_ = writeBody(w, status, jsonContentType, body)
+
+	return nil
+}
+
+// JSONWithLastModified converts data to JSON and sends it with a Last-Modified header.
+// If the request is a GET or HEAD whose If-Modified-Since header is at or after modTime,
+// http.StatusNotModified is sent without a body instead.
+// modTime is truncated to seconds, the precision of HTTP dates.
+// A zero modTime sends the response without Last-Modified.
+func JSONWithLastModified(w http.ResponseWriter, r *http.Request, status int, modTime time.Time, data any) error {
+	if modTime.IsZero() {
+		_ = writeJSON(w, status, data)
+		return nil
+	}
+
+	modTime = modTime.Truncate(time.Second)
+	w.Header().Set("Last-Modified", modTime.UTC().Format(http.TimeFormat))
+
+	if isConditional(r, status) && notModifiedSince(r, modTime) {
+		w.WriteHeader(http.StatusNotModified)
+		return nil
+	}
+
+	_ = writeJSON(w, status, data)
+
+	return nil
+}
+
+// notModifiedSince reports whether the If-Modified-Since header is at or after modTime.
+// The header is ignored when If-None-Match is present, as ETags take precedence.
+func notModifiedSince(r *http.Request, modTime time.Time) bool {
+	if r.Header.Get("If-None-Match") != "" {
+		return false
+	}
+
+	since, err := http.ParseTime(r.Header.Get("If-Modified-Since"))
+	if err != nil {
+		return false
+	}
+
+	return !modTime.After(since)
+}
+
+// ConditionalGET answers GET and HEAD requests with http.StatusNotModified
+// before calling the handler when their If-None-Match header matches the ETag
+// returned by etagFn, so the handler work is skipped entirely.
+// etagFn should be cheap, like deriving the ETag from a version number.
+// If it returns an empty string, the request goes through unchanged.
+func ConditionalGET(etagFn func(*http.Request) string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			etag := etagFn(r)
+			if etag == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			etag = quoteETag(etag)
+			w.Header().Set("ETag", etag)
+
+			if isConditional(r, http.StatusOK) && etagMatch(r.Header.Get("If-None-Match"), etag) {
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// quoteETag quotes etag unless it is already a quoted or weak ETag.
+func quoteETag(etag string) string {
+	if strings.HasPrefix(etag, `"`) || strings.HasPrefix(etag, `W/"`) {
+		return etag
+	}
+
+	return `"` + etag + `"`
+}
+
+// isConditional reports whether a conditional GET can be answered with http.StatusNotModified.
+func isConditional(r *http.Request, status int) bool {
+	return (r.Method == http.MethodGet || r.Method == http.MethodHead) &&
+		status >= 200 && status < 300
+}
+
+// etagMatch reports whether the If-None-Match header matches etag,
+// using the weak comparison.
+func etagMatch(header, etag string) bool {
+	if header == "" {
+		return false
+	}
+
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// CacheControl sets the Cache-Control header allowing the response to be cached for maxAge,
+// like `public, max-age=60`, and the matching Expires header for HTTP/1.0 caches.
+// A public response can be stored by shared caches like CDNs,
+// a private one only by the browser of the client.
+// Call it before writing the response.
+func CacheControl(w http.ResponseWriter, maxAge time.Duration, public bool) {
+	if maxAge < 0 {
+		maxAge = 0
+	}
+
+	visibility := "private"
+	if public {
+		visibility = "public"
+	}
+
+	seconds := int64(maxAge / time.Second)
+
+	h := w.Header()
+	h.Set("Cache-Control", visibility+", max-age="+strconv.FormatInt(seconds, 10))
+	h.Set("Expires", time.Now().Add(time.Duration(seconds)*time.Second).UTC().Format(http.TimeFormat))
+}
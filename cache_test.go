@@ -0,0 +1,183 @@
+package httpwr
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestJSONWithETag(t *testing.T) {
+	data := M{"version": 1}
+	h := F(func(w http.ResponseWriter, r *http.Request) error {
+		return JSONWithETag(w, r, http.StatusOK, data)
+	})
+
+	req := httptest.NewRequest("GET", "/etag", nil)
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	resp := w.Result()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected http status ok, got %d", resp.StatusCode)
+	}
+
+	etag := resp.Header.Get("ETag")
+	if etag == "" {
+		t.Fatalf("expected ETag header, got none")
+	}
+
+	bts, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("got error: %v", err)
+	}
+	if !strings.Contains(string(bts), `"version":1`) {
+		t.Fatalf("%q does not contain %q", string(bts), `"version":1`)
+	}
+
+	req = httptest.NewRequest("GET", "/etag", nil)
+	req.Header.Set("If-None-Match", etag)
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	resp = w.Result()
+
+	if resp.StatusCode != http.StatusNotModified {
+		t.Fatalf("expected http status %d, got %d", http.StatusNotModified, resp.StatusCode)
+	}
+
+	bts, err = io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("got error: %v", err)
+	}
+	if len(bts) != 0 {
+		t.Fatalf("expected empty body, got %q", string(bts))
+	}
+}
+
+func TestJSONWithETagMismatch(t *testing.T) {
+	req := httptest.NewRequest("GET", "/etag", nil)
+	req.Header.Set("If-None-Match", `"stale"`)
+	w := httptest.NewRecorder()
+	F(func(w http.ResponseWriter, r *http.Request) error {
+		return JSONWithETag(w, r, http.StatusOK, M{"version": 2})
+	}).ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected http status ok, got %d", w.Code)
+	}
+}
+
+func TestConditionalGET(t *testing.T) {
+	var calls int
+	h := ConditionalGET(func(r *http.Request) string {
+		return "v42"
+	})(F(func(w http.ResponseWriter, r *http.Request) error {
+		calls++
+		return OK(w, http.StatusOK, OKMsg)
+	}))
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/config", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected http status ok, got %d", w.Code)
+	}
+	if got := w.Header().Get("ETag"); got != `"v42"` {
+		t.Fatalf("expected ETag %q, got %q", `"v42"`, got)
+	}
+	if calls != 1 {
+		t.Fatalf("expected handler to be called once, got %d", calls)
+	}
+
+	req := httptest.NewRequest("GET", "/config", nil)
+	req.Header.Set("If-None-Match", `"v42"`)
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+
+	if w.Code != http.StatusNotModified {
+		t.Fatalf("expected http status %d, got %d", http.StatusNotModified, w.Code)
+	}
+	if calls != 1 {
+		t.Fatalf("expected handler not to be called on a cache hit, got %d calls", calls)
+	}
+}
+
+func TestCacheControl(t *testing.T) {
+	tests := []struct {
+		name   string
+		maxAge time.Duration
+		public bool
+		want   string
+	}{
+		{name: "public", maxAge: 5 * time.Minute, public: true, want: "public, max-age=300"},
+		{name: "private", maxAge: 90 * time.Second, want: "private, max-age=90"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			CacheControl(w, tt.maxAge, tt.public)
+			_ = OK(w, http.StatusOK, OKMsg)
+
+			if got := w.Header().Get("Cache-Control"); got != tt.want {
+				t.Fatalf("expected Cache-Control %q, got %q", tt.want, got)
+			}
+
+			expires, err := http.ParseTime(w.Header().Get("Expires"))
+			if err != nil {
+				t.Fatalf("got error: %v", err)
+			}
+			if d := time.Until(expires); d > tt.maxAge || d < tt.maxAge-2*time.Second {
+				t.Fatalf("expected Expires in %s, got %s", tt.maxAge, d)
+			}
+		})
+	}
+}
+
+func TestJSONWithLastModified(t *testing.T) {
+	modTime := time.Date(2024, 3, 1, 10, 30, 0, 500, time.UTC)
+	h := F(func(w http.ResponseWriter, r *http.Request) error {
+		return JSONWithLastModified(w, r, http.StatusOK, modTime, M{"version": 1})
+	})
+
+	t.Run("fresh request", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest("GET", "/config", nil))
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected http status ok, got %d", w.Code)
+		}
+		if got := w.Header().Get("Last-Modified"); got != "Fri, 01 Mar 2024 10:30:00 GMT" {
+			t.Fatalf("unexpected Last-Modified %q", got)
+		}
+		if w.Body.String() != `{"version":1}`+"\n" {
+			t.Fatalf("unexpected body %q", w.Body.String())
+		}
+	})
+
+	t.Run("not modified", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/config", nil)
+		req.Header.Set("If-Modified-Since", "Fri, 01 Mar 2024 10:30:00 GMT")
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+
+		if w.Code != http.StatusNotModified {
+			t.Fatalf("expected http status not modified, got %d", w.Code)
+		}
+		if w.Body.Len() != 0 {
+			t.Fatalf("expected no body, got %q", w.Body.String())
+		}
+	})
+
+	t.Run("modified", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/config", nil)
+		req.Header.Set("If-Modified-Since", "Fri, 01 Mar 2024 10:29:59 GMT")
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected http status ok, got %d", w.Code)
+		}
+	})
+}
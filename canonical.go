@@ -0,0 +1,88 @@
+package httpwr
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"sort"
+)
+
+// CanonicalJSON sends v as canonical JSON: compact, with the object keys
+// sorted recursively, so equivalent values always produce the same bytes.
+// Use it when the body is signed or hashed.
+func CanonicalJSON(w http.ResponseWriter, status int, v any) error {
+	body, err := canonicalJSON(v)
+	if err != nil {
+		return err
+	}
+
+	return writeBody(w, status, jsonContentType, body)
+}
+
+// canonicalJSON encodes v with recursively sorted object keys.
+func canonicalJSON(v any) ([]byte, error) {
+	bts, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(bts))
+	dec.UseNumber()
+
+	var doc any
+	if err := dec.Decode(&doc); err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err := writeCanonical(&buf, doc); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func writeCanonical(buf *bytes.Buffer, v any) error {
+	switch v := v.(type) {
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		buf.WriteByte('{')
+		for i, k := range keys {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			if err := writeCanonical(buf, k); err != nil {
+				return err
+			}
+			buf.WriteByte(':')
+			if err := writeCanonical(buf, v[k]); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte('}')
+	case []any:
+		buf.WriteByte('[')
+		for i, elem := range v {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			if err := writeCanonical(buf, elem); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte(']')
+	default:
+		bts, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		buf.Write(bts)
+	}
+
+	return nil
+}
@@ -0,0 +1,35 @@
+package httpwr
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCanonicalJSON(t *testing.T) {
+	type item struct {
+		Zeta  int `json:"zeta"`
+		Alpha int `json:"alpha"`
+	}
+
+	a := M{"b": 1, "a": M{"y": true, "x": []any{item{Zeta: 1, Alpha: 2}}}}
+	b := M{"a": M{"x": []any{M{"alpha": 2, "zeta": 1}}, "y": true}, "b": 1}
+
+	serve := func(v any) string {
+		w := httptest.NewRecorder()
+		if err := CanonicalJSON(w, http.StatusOK, v); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		return w.Body.String()
+	}
+
+	gotA, gotB := serve(a), serve(b)
+	if gotA != gotB {
+		t.Fatalf("expected identical output, got %q and %q", gotA, gotB)
+	}
+
+	want := `{"a":{"x":[{"alpha":2,"zeta":1}],"y":true},"b":1}`
+	if gotA != want {
+		t.Fatalf("expected %q, got %q", want, gotA)
+	}
+}
@@ -0,0 +1,66 @@
+package httpwr
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+)
+
+// WrapIf is the same as Wrap, it wraps err with status, and returns nil if err is nil.
+// It reads better in handlers, like `return httpwr.WrapIf(http.StatusBadGateway, err)`.
+func WrapIf(status int, err error) error {
+	return Wrap(status, err)
+}
+
+// Check wraps err with status for an early return, like
+//
+//	if err := httpwr.Check(db.Ping(), http.StatusServiceUnavailable); err != nil {
+//		return err
+//	}
+//
+// It returns nil if err is nil, and err as is if it is already an Error, so its status is kept.
+func Check(err error, status int) error {
+	if err == nil {
+		return nil
+	}
+
+	if errors.Is(err, Error{}) {
+		return err
+	}
+
+	return Wrap(status, err)
+}
+
+// isNotFound reports whether an error means the resource does not exist.
+var isNotFound = isNoRows
+
+func isNoRows(err error) bool {
+	return errors.Is(err, sql.ErrNoRows)
+}
+
+// SetNotFoundFunc sets the predicate used by CheckNotFound to recognize the errors
+// meaning the resource does not exist, like the not found error of a database driver.
+// The default recognizes sql.ErrNoRows. Pass nil to restore it.
+// Call it before serving requests, it is not safe for concurrent use.
+func SetNotFoundFunc(fn func(error) bool) {
+	if fn == nil {
+		fn = isNoRows
+	}
+
+	isNotFound = fn
+}
+
+// CheckNotFound is like Check, but errors recognized by the predicate set by SetNotFoundFunc,
+// like sql.ErrNoRows, are wrapped with http.StatusNotFound,
+// and any other error with http.StatusInternalServerError.
+func CheckNotFound(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	if isNotFound(err) {
+		return Check(err, http.StatusNotFound)
+	}
+
+	return Check(err, http.StatusInternalServerError)
+}
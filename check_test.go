@@ -0,0 +1,66 @@
+package httpwr
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestCheck(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+	}{
+		{name: "nil", err: nil},
+		{name: "generic error", err: errBoom, wantStatus: http.StatusServiceUnavailable},
+		{name: "error keeps its status", err: Wrap(http.StatusConflict, errBoom), wantStatus: http.StatusConflict},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Check(tt.err, http.StatusServiceUnavailable)
+			assertStatus(t, err, tt.wantStatus)
+		})
+	}
+
+	if err := WrapIf(http.StatusBadGateway, nil); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	assertStatus(t, WrapIf(http.StatusBadGateway, errBoom), http.StatusBadGateway)
+}
+
+func TestCheckNotFound(t *testing.T) {
+	assertStatus(t, CheckNotFound(nil), 0)
+	assertStatus(t, CheckNotFound(fmt.Errorf("get user: %w", sql.ErrNoRows)), http.StatusNotFound)
+	assertStatus(t, CheckNotFound(errors.New("connection refused")), http.StatusInternalServerError)
+
+	errMissing := errors.New("missing")
+	SetNotFoundFunc(func(err error) bool { return errors.Is(err, errMissing) })
+	t.Cleanup(func() { SetNotFoundFunc(nil) })
+
+	assertStatus(t, CheckNotFound(errMissing), http.StatusNotFound)
+	assertStatus(t, CheckNotFound(sql.ErrNoRows), http.StatusInternalServerError)
+}
+
+// assertStatus fails the test unless err is an Error with the given status,
+// or nil if status is zero.
+func assertStatus(t *testing.T, err error, status int) {
+	t.Helper()
+
+	if status == 0 {
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		return
+	}
+
+	var herr Error
+	if !errors.As(err, &herr) || herr.Status != status {
+		t.Fatalf("expected Error with http status %d, got %v", status, err)
+	}
+}
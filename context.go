@@ -0,0 +1,30 @@
+package httpwr
+
+import "context"
+
+// ctxKey is the type of the context keys used by this package.
+type ctxKey int
+
+const (
+	bufferKey ctxKey = iota
+	requestIDKey
+	flagsKey
+)
+
+// valueKey is the context key of the values stored by WithValue.
+// Each T gets its own key type, so values of different types never collide.
+type valueKey[T any] struct{}
+
+// WithValue returns a copy of ctx carrying v, retrieved with Value.
+// Only one value per type is stored, so use your own type, like a User struct,
+// instead of a string.
+func WithValue[T any](ctx context.Context, v T) context.Context {
+	return context.WithValue(ctx, valueKey[T]{}, v)
+}
+
+// Value returns the value of type T stored by WithValue.
+// It returns false if there is none.
+func Value[T any](ctx context.Context) (T, bool) {
+	v, ok := ctx.Value(valueKey[T]{}).(T)
+	return v, ok
+}
@@ -0,0 +1,28 @@
+package httpwr
+
+import (
+	"context"
+	"testing"
+)
+
+func TestValue(t *testing.T) {
+	type user struct{ Name string }
+	type tenant struct{ Name string }
+
+	ctx := WithValue(context.Background(), user{Name: "samuel"})
+	ctx = WithValue(ctx, tenant{Name: "acme"})
+
+	u, ok := Value[user](ctx)
+	if !ok || u.Name != "samuel" {
+		t.Fatalf("expected user %q, got %q (found: %v)", "samuel", u.Name, ok)
+	}
+
+	tn, ok := Value[tenant](ctx)
+	if !ok || tn.Name != "acme" {
+		t.Fatalf("expected tenant %q, got %q (found: %v)", "acme", tn.Name, ok)
+	}
+
+	if _, ok := Value[string](ctx); ok {
+		t.Fatalf("expected no string value")
+	}
+}
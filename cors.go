@@ -0,0 +1,110 @@
+package httpwr
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CORSOptions configures the CORS middleware.
+type CORSOptions struct {
+	// AllowedOrigins is the list of origins allowed to make cross-origin requests.
+	// Use "*" to allow any origin, which can not be combined with AllowCredentials.
+	AllowedOrigins []string
+
+	// AllowedMethods is the list of methods allowed in cross-origin requests.
+	// If empty, GET, HEAD and POST are allowed.
+	AllowedMethods []string
+
+	// AllowedHeaders is the list of non simple headers the client can send.
+	AllowedHeaders []string
+
+	// AllowCredentials allows the request to include cookies and auth headers.
+	AllowCredentials bool
+
+	// MaxAge is how long the result of a preflight request can be cached.
+	MaxAge time.Duration
+}
+
+// CORS handles the cross-origin requests from the allowed origins.
+// Preflight requests are answered with http.StatusNoContent without calling the handler.
+// Requests from an origin that is not allowed get no CORS headers,
+// so the browser blocks them.
+//
+// It panics if a wildcard origin is combined with AllowCredentials,
+// because browsers reject that combination.
+func CORS(opts CORSOptions) Middleware {
+	allowAll := false
+	origins := make(map[string]bool, len(opts.AllowedOrigins))
+	for _, origin := range opts.AllowedOrigins {
+		if origin == "*" {
+			allowAll = true
+			continue
+		}
+		origins[strings.ToLower(origin)] = true
+	}
+
+	if allowAll && opts.AllowCredentials {
+		panic("httpwr: CORS wildcard origin can not be used with credentials")
+	}
+
+	methods := opts.AllowedMethods
+	if len(methods) == 0 {
+		methods = []string{http.MethodGet, http.MethodHead, http.MethodPost}
+	}
+	allowMethods := strings.Join(methods, ", ")
+	allowHeaders := strings.Join(opts.AllowedHeaders, ", ")
+
+	allowed := func(origin string) bool {
+		return allowAll || origins[strings.ToLower(origin)]
+	}
+
+	setOrigin := func(h http.Header, origin string) {
+		if allowAll {
+			h.Set("Access-Control-Allow-Origin", "*")
+		} else {
+			h.Set("Access-Control-Allow-Origin", origin)
+		}
+		if opts.AllowCredentials {
+			h.Set("Access-Control-Allow-Credentials", "true")
+		}
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			origin := r.Header.Get("Origin")
+			h := w.Header()
+
+			preflight := r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != ""
+			if preflight {
+				h.Add("Vary", "Origin")
+				h.Add("Vary", "Access-Control-Request-Method")
+				h.Add("Vary", "Access-Control-Request-Headers")
+
+				if origin != "" && allowed(origin) {
+					setOrigin(h, origin)
+					h.Set("Access-Control-Allow-Methods", allowMethods)
+					if allowHeaders != "" {
+						h.Set("Access-Control-Allow-Headers", allowHeaders)
+					}
+					if opts.MaxAge > 0 {
+						h.Set("Access-Control-Max-Age", strconv.Itoa(int(opts.MaxAge.Seconds())))
+					}
+				}
+
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+
+			if !allowAll {
+				h.Add("Vary", "Origin")
+			}
+			if origin != "" && allowed(origin) {
+				setOrigin(h, origin)
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
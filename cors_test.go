@@ -0,0 +1,104 @@
+package httpwr
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestCORS(t *testing.T) {
+	var called bool
+	h := CORS(CORSOptions{
+		AllowedOrigins:   []string{"https://app.example.com"},
+		AllowedMethods:   []string{http.MethodGet, http.MethodPut},
+		AllowedHeaders:   []string{"Content-Type", "Authorization"},
+		AllowCredentials: true,
+		MaxAge:           10 * time.Minute,
+	})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	t.Run("preflight", func(t *testing.T) {
+		called = false
+		req := httptest.NewRequest("OPTIONS", "/items", nil)
+		req.Header.Set("Origin", "https://app.example.com")
+		req.Header.Set("Access-Control-Request-Method", "PUT")
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+
+		if w.Code != http.StatusNoContent {
+			t.Fatalf("expected http status %d, got %d", http.StatusNoContent, w.Code)
+		}
+		if called {
+			t.Fatalf("expected preflight not to reach the handler")
+		}
+
+		want := map[string]string{
+			"Access-Control-Allow-Origin":      "https://app.example.com",
+			"Access-Control-Allow-Methods":     "GET, PUT",
+			"Access-Control-Allow-Headers":     "Content-Type, Authorization",
+			"Access-Control-Allow-Credentials": "true",
+			"Access-Control-Max-Age":           "600",
+		}
+		for k, v := range want {
+			if got := w.Header().Get(k); got != v {
+				t.Fatalf("expected %s %q, got %q", k, v, got)
+			}
+		}
+	})
+
+	t.Run("allowed origin", func(t *testing.T) {
+		called = false
+		req := httptest.NewRequest("GET", "/items", nil)
+		req.Header.Set("Origin", "https://app.example.com")
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+
+		if !called {
+			t.Fatalf("expected handler to be called")
+		}
+		if got := w.Header().Get("Access-Control-Allow-Origin"); got != "https://app.example.com" {
+			t.Fatalf("expected allowed origin, got %q", got)
+		}
+	})
+
+	t.Run("disallowed origin", func(t *testing.T) {
+		called = false
+		req := httptest.NewRequest("GET", "/items", nil)
+		req.Header.Set("Origin", "https://evil.example.com")
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+
+		if !called {
+			t.Fatalf("expected handler to be called")
+		}
+		if got := w.Header().Get("Access-Control-Allow-Origin"); got != "" {
+			t.Fatalf("expected no allowed origin, got %q", got)
+		}
+	})
+}
+
+func TestCORSWildcard(t *testing.T) {
+	h := CORS(CORSOptions{AllowedOrigins: []string{"*"}})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	req := httptest.NewRequest("GET", "/items", nil)
+	req.Header.Set("Origin", "https://any.example.com")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+
+	if got := w.Header().Get("Access-Control-Allow-Origin"); got != "*" {
+		t.Fatalf("expected wildcard origin, got %q", got)
+	}
+}
+
+func TestCORSWildcardWithCredentials(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatalf("expected panic for wildcard origin with credentials")
+		}
+	}()
+
+	CORS(CORSOptions{AllowedOrigins: []string{"*"}, AllowCredentials: true})
+}
@@ -0,0 +1,30 @@
+package httpwr
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+)
+
+// WithDeadline returns a copy of the request context that is done after d,
+// for an operation like a database query. Always defer cancel, like
+//
+//	ctx, cancel := httpwr.WithDeadline(r, 2*time.Second)
+//	defer cancel()
+//
+// The context is also done when the client goes away, since it derives from the request.
+func WithDeadline(r *http.Request, d time.Duration) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(r.Context(), d)
+}
+
+// DeadlineError returns the error of ctx wrapped with http.StatusGatewayTimeout
+// if its deadline was exceeded, so it can be returned from the handler.
+// It returns nil if the deadline was not exceeded.
+func DeadlineError(ctx context.Context) error {
+	if err := ctx.Err(); errors.Is(err, context.DeadlineExceeded) {
+		return Wrap(http.StatusGatewayTimeout, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,46 @@
+package httpwr
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestDeadlineError(t *testing.T) {
+	t.Run("deadline exceeded", func(t *testing.T) {
+		ctx, cancel := WithDeadline(httptest.NewRequest("GET", "/", nil), time.Millisecond)
+		defer cancel()
+		<-ctx.Done()
+
+		err := DeadlineError(ctx)
+
+		var herr Error
+		if !errors.As(err, &herr) || herr.Status != http.StatusGatewayTimeout {
+			t.Fatalf("expected gateway timeout Error, got %v", err)
+		}
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("expected %v to be context.DeadlineExceeded", err)
+		}
+	})
+
+	t.Run("within deadline", func(t *testing.T) {
+		ctx, cancel := WithDeadline(httptest.NewRequest("GET", "/", nil), time.Minute)
+		defer cancel()
+
+		if err := DeadlineError(ctx); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+	})
+
+	t.Run("canceled", func(t *testing.T) {
+		ctx, cancel := WithDeadline(httptest.NewRequest("GET", "/", nil), time.Minute)
+		cancel()
+
+		if err := DeadlineError(ctx); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+	})
+}
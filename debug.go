@@ -0,0 +1,75 @@
+package httpwr
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"runtime"
+)
+
+// DebugErrorHandler is like DefaultErrorHandler, but the body also has a `trace` array
+// with every error of the chain of err, from the outermost one, with its type
+// and the stack frames captured by WrapTrace.
+//
+// Use it with NewWithHandlerCtx, which gives it the Error itself with its stack trace.
+//
+// It is meant for local development only: it leaks the internals of the application,
+// like file paths and error messages, so never use it in production.
+func DebugErrorHandler(w http.ResponseWriter, r *http.Request, status int, err error) {
+	type body struct {
+		Status int          `json:"status,omitempty"`
+		Err    string       `json:"error"`
+		Trace  []debugError `json:"trace"`
+	}
+
+	_ = writeJSON(w, status, body{
+		Status: bodyStatus(status),
+		Err:    err.Error(),
+		Trace:  errorTrace(err),
+	})
+}
+
+// debugError is an error of the chain rendered by DebugErrorHandler.
+type debugError struct {
+	Message string   `json:"message"`
+	Type    string   `json:"type"`
+	Stack   []string `json:"stack,omitempty"`
+}
+
+// errorTrace returns the chain of err, following errors.Unwrap.
+func errorTrace(err error) []debugError {
+	var trace []debugError
+	for ; err != nil; err = errors.Unwrap(err) {
+		de := debugError{
+			Message: err.Error(),
+			Type:    fmt.Sprintf("%T", err),
+		}
+
+		if herr, ok := err.(Error); ok {
+			de.Stack = stackFrames(herr.StackTrace())
+		}
+
+		trace = append(trace, de)
+	}
+
+	return trace
+}
+
+// stackFrames resolves the program counters into `function file:line` lines.
+func stackFrames(pcs []uintptr) []string {
+	if len(pcs) == 0 {
+		return nil
+	}
+
+	var lines []string
+	frames := runtime.CallersFrames(pcs)
+	for {
+		frame, more := frames.Next()
+		lines = append(lines, fmt.Sprintf("%s %s:%d", frame.Function, frame.File, frame.Line))
+		if !more {
+			break
+		}
+	}
+
+	return lines
+}
@@ -0,0 +1,56 @@
+package httpwr
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestDebugErrorHandler(t *testing.T) {
+	h := NewWithHandlerCtx(HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		cause := fmt.Errorf("read config: %w", io.ErrUnexpectedEOF)
+		return fmt.Errorf("load: %w", WrapTrace(http.StatusBadGateway, cause))
+	}), DebugErrorHandler)
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+
+	if w.Code != http.StatusBadGateway {
+		t.Fatalf("expected http status bad gateway, got %d", w.Code)
+	}
+
+	var body struct {
+		Status int          `json:"status"`
+		Err    string       `json:"error"`
+		Trace  []debugError `json:"trace"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&body); err != nil {
+		t.Fatalf("got error: %v", err)
+	}
+
+	if body.Err != "read config: unexpected EOF" {
+		t.Fatalf("unexpected error message %q", body.Err)
+	}
+
+	wantTypes := []string{"httpwr.Error", "*fmt.wrapError", "*errors.errorString"}
+	if len(body.Trace) != len(wantTypes) {
+		t.Fatalf("expected %d errors in the trace, got %+v", len(wantTypes), body.Trace)
+	}
+	for i, want := range wantTypes {
+		if body.Trace[i].Type != want {
+			t.Fatalf("expected error %d to be a %s, got %s", i, want, body.Trace[i].Type)
+		}
+	}
+	if body.Trace[2].Message != "unexpected EOF" {
+		t.Fatalf("unexpected root cause %q", body.Trace[2].Message)
+	}
+
+	stack := body.Trace[0].Stack
+	if len(stack) == 0 || !strings.Contains(stack[0], "TestDebugErrorHandler") {
+		t.Fatalf("expected the stack trace of WrapTrace, got %v", stack)
+	}
+}
@@ -0,0 +1,214 @@
+package httpwr
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+	"unicode/utf8"
+)
+
+// DefaultMaxBodyBytes is the maximum size of a request body read by the decode helpers
+// when no other limit is given.
+var DefaultMaxBodyBytes int64 = 1 << 20
+
+// DecodeOptions customizes how the request body is decoded.
+type DecodeOptions struct {
+	// RequireUTF8 rejects request bodies that are not valid UTF-8
+	// instead of silently replacing the invalid bytes.
+	RequireUTF8 bool
+
+	// DisallowUnknownFields rejects request bodies with fields
+	// that do not match any field of the destination.
+	DisallowUnknownFields bool
+
+	// MaxBytes is the maximum size of the request body.
+	// Larger bodies are rejected with http.StatusRequestEntityTooLarge.
+	// If zero, DefaultMaxBodyBytes is used. If negative, the size is not limited.
+	MaxBytes int64
+}
+
+// Decode decodes the JSON request body into v.
+// Any decoding error is wrapped with http.StatusBadRequest.
+func Decode[T any](r *http.Request, v *T) error {
+	return decodeJSON(r, v, DecodeOptions{})
+}
+
+// DecodeStrict is like Decode, but unknown fields in the body are rejected.
+func DecodeStrict[T any](r *http.Request, v *T) error {
+	return decodeJSON(r, v, DecodeOptions{DisallowUnknownFields: true})
+}
+
+// DecodeLimited is like Decode, but the request body can not be larger than max bytes.
+// The bytes are counted as they are read, so the limit also applies to chunked bodies
+// sent without a Content-Length, and a larger body is rejected with
+// http.StatusRequestEntityTooLarge as soon as the limit is crossed.
+func DecodeLimited[T any](r *http.Request, v *T, max int64) error {
+	return decodeJSON(r, v, DecodeOptions{MaxBytes: max})
+}
+
+// DecodeWithOptions is like Decode, but you can customize how the body is decoded.
+func DecodeWithOptions[T any](r *http.Request, v *T, opts DecodeOptions) error {
+	return decodeJSON(r, v, opts)
+}
+
+func decodeJSON(r *http.Request, v any, opts DecodeOptions) error {
+	if r.Body == nil {
+		return Wrap(http.StatusBadRequest, errors.New("request body is empty"))
+	}
+
+	max := opts.MaxBytes
+	if max == 0 {
+		max = DefaultMaxBodyBytes
+	}
+	if max > 0 {
+		r.Body = http.MaxBytesReader(nil, r.Body, max)
+	}
+
+	var body io.Reader = r.Body
+	if opts.RequireUTF8 {
+		bts, err := io.ReadAll(r.Body)
+		if err != nil {
+			return wrapDecodeError(err)
+		}
+		if !utf8.Valid(bts) {
+			return Wrap(http.StatusBadRequest, errors.New("request body is not valid UTF-8"))
+		}
+		body = bytes.NewReader(bts)
+	}
+
+	dec := json.NewDecoder(body)
+	if opts.DisallowUnknownFields {
+		dec.DisallowUnknownFields()
+	}
+
+	return wrapDecodeError(dec.Decode(v))
+}
+
+// readBody reads the whole body of r, at most max bytes if max is positive,
+// and restores it, so it can be read again by the handler.
+// A larger body is rejected with http.StatusRequestEntityTooLarge,
+// and any other read error with http.StatusBadRequest.
+func readBody(r *http.Request, max int64) ([]byte, error) {
+	if r.Body == nil {
+		r.Body = http.NoBody
+		return nil, nil
+	}
+
+	var src io.Reader = r.Body
+	if max > 0 {
+		src = http.MaxBytesReader(nil, r.Body, max)
+	}
+
+	body, err := io.ReadAll(src)
+	if tooLarge := tooLargeError(err); tooLarge != nil {
+		return nil, tooLarge
+	}
+	if err != nil {
+		return nil, Wrap(http.StatusBadRequest, err)
+	}
+	r.Body = io.NopCloser(bytes.NewReader(body))
+
+	return body, nil
+}
+
+// tooLargeError returns the error sent for a body larger than the limit of a
+// http.MaxBytesReader, wrapped with http.StatusRequestEntityTooLarge,
+// or nil if err is not caused by the limit.
+func tooLargeError(err error) error {
+	var maxErr *http.MaxBytesError
+	if !errors.As(err, &maxErr) {
+		return nil
+	}
+
+	return Errorf(http.StatusRequestEntityTooLarge, "request body is larger than %d bytes", maxErr.Limit)
+}
+
+// wrapDecodeError wraps err with the status matching the cause of the failure.
+func wrapDecodeError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	if tooLarge := tooLargeError(err); tooLarge != nil {
+		return tooLarge
+	}
+
+	return Wrap(http.StatusBadRequest, decodeError(err))
+}
+
+// decodeError converts errors from encoding/json into messages
+// that are meaningful for the client, like `invalid value at /user/age: expected number, got string`
+// or `malformed JSON at byte offset 12: ...`.
+func decodeError(err error) error {
+	var (
+		typeErr   *json.UnmarshalTypeError
+		syntaxErr *json.SyntaxError
+	)
+	switch {
+	case errors.Is(err, io.EOF):
+		return errors.New("request body is empty")
+	case errors.Is(err, io.ErrUnexpectedEOF):
+		return errors.New("request body is not complete JSON")
+	case errors.As(err, &syntaxErr):
+		return fmt.Errorf("malformed JSON at byte offset %d: %s", syntaxErr.Offset, syntaxErr.Error())
+	case errors.As(err, &typeErr):
+		return fmt.Errorf("invalid value at %s: expected %s, got %s",
+			jsonPointer(typeErr.Field), jsonKind(typeErr.Type), typeErr.Value)
+	case strings.HasPrefix(err.Error(), "json: unknown field "):
+		// encoding/json has no typed error for unknown fields.
+		return errors.New(strings.TrimPrefix(err.Error(), "json: "))
+	default:
+		return err
+	}
+}
+
+// jsonPointer converts a dotted field path like "user.age"
+// into a JSON Pointer (RFC 6901) like "/user/age".
+func jsonPointer(field string) string {
+	if field == "" {
+		return ""
+	}
+
+	replacer := strings.NewReplacer("~", "~0", "/", "~1")
+
+	var sb strings.Builder
+	for _, part := range strings.Split(field, ".") {
+		sb.WriteByte('/')
+		sb.WriteString(replacer.Replace(part))
+	}
+
+	return sb.String()
+}
+
+// jsonKind returns the JSON name of the kind of value t expects.
+func jsonKind(t reflect.Type) string {
+	if t == nil {
+		return "value"
+	}
+
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return "boolean"
+	case reflect.String:
+		return "string"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.Slice, reflect.Array:
+		return "array"
+	case reflect.Map, reflect.Struct:
+		return "object"
+	default:
+		return t.String()
+	}
+}
@@ -0,0 +1,237 @@
+package httpwr
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestDecode(t *testing.T) {
+	type user struct {
+		Name string `json:"name"`
+		Age  int    `json:"age"`
+	}
+
+	var got user
+	body := `{"name":"sam","age":23}`
+	req := httptest.NewRequest("POST", "/decode", strings.NewReader(body))
+
+	if err := Decode(req, &got); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if got.Name != "sam" || got.Age != 23 {
+		t.Fatalf("unexpected decoded value: %+v", got)
+	}
+}
+
+func TestDecodeNestedTypeMismatch(t *testing.T) {
+	type payload struct {
+		User struct {
+			Age int `json:"age"`
+		} `json:"user"`
+	}
+
+	body := `{"user":{"age":"twenty"}}`
+	req := httptest.NewRequest("POST", "/decode", strings.NewReader(body))
+	w := httptest.NewRecorder()
+	F(func(w http.ResponseWriter, r *http.Request) error {
+		var p payload
+		return Decode(r, &p)
+	}).ServeHTTP(w, req)
+	resp := w.Result()
+
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected http status %d, got %d", http.StatusBadRequest, resp.StatusCode)
+	}
+
+	bts, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("got error: %v", err)
+	}
+	if !strings.Contains(string(bts), "/user/age") {
+		t.Fatalf("%q does not contain %q", string(bts), "/user/age")
+	}
+}
+
+func TestDecodeEmptyBody(t *testing.T) {
+	var v M
+	req := httptest.NewRequest("POST", "/decode", strings.NewReader(""))
+
+	err := Decode(req, &v)
+	if !errors.Is(err, Error{}) {
+		t.Fatalf("expected Error, got %v", err)
+	}
+}
+
+func TestDecodeRequireUTF8(t *testing.T) {
+	body := "{\"name\":\"caf\xe9\"}"
+
+	t.Run("invalid", func(t *testing.T) {
+		var v M
+		req := httptest.NewRequest("POST", "/decode", strings.NewReader(body))
+
+		err := DecodeWithOptions(req, &v, DecodeOptions{RequireUTF8: true})
+
+		var herr Error
+		if !errors.As(err, &herr) || herr.Status != http.StatusBadRequest {
+			t.Fatalf("expected bad request Error, got %v", err)
+		}
+		if !strings.Contains(herr.Error(), "UTF-8") {
+			t.Fatalf("%q does not contain %q", herr.Error(), "UTF-8")
+		}
+	})
+
+	t.Run("not required", func(t *testing.T) {
+		var v M
+		req := httptest.NewRequest("POST", "/decode", strings.NewReader(body))
+
+		if err := Decode(req, &v); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+	})
+}
+
+func TestDecodeStrict(t *testing.T) {
+	type user struct {
+		Name string `json:"name"`
+	}
+
+	t.Run("unknown field", func(t *testing.T) {
+		var v user
+		req := httptest.NewRequest("POST", "/decode", strings.NewReader(`{"name":"sam","admin":true}`))
+
+		err := DecodeStrict(req, &v)
+
+		var herr Error
+		if !errors.As(err, &herr) || herr.Status != http.StatusBadRequest {
+			t.Fatalf("expected bad request Error, got %v", err)
+		}
+		if !strings.Contains(herr.Error(), `"admin"`) {
+			t.Fatalf("%q does not contain %q", herr.Error(), `"admin"`)
+		}
+	})
+
+	t.Run("known fields", func(t *testing.T) {
+		var v user
+		req := httptest.NewRequest("POST", "/decode", strings.NewReader(`{"name":"sam"}`))
+
+		if err := DecodeStrict(req, &v); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if v.Name != "sam" {
+			t.Fatalf("expected name %q, got %q", "sam", v.Name)
+		}
+	})
+}
+
+func TestDecodeLimited(t *testing.T) {
+	t.Run("over limit", func(t *testing.T) {
+		var v M
+		body := `{"name":"` + strings.Repeat("a", 100) + `"}`
+		req := httptest.NewRequest("POST", "/decode", strings.NewReader(body))
+
+		err := DecodeLimited(req, &v, 16)
+
+		var herr Error
+		if !errors.As(err, &herr) || herr.Status != http.StatusRequestEntityTooLarge {
+			t.Fatalf("expected request entity too large Error, got %v", err)
+		}
+	})
+
+	t.Run("under limit", func(t *testing.T) {
+		var v M
+		req := httptest.NewRequest("POST", "/decode", strings.NewReader(`{"name":"sam"}`))
+
+		if err := DecodeLimited(req, &v, 1024); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+	})
+
+	t.Run("default limit", func(t *testing.T) {
+		prev := DefaultMaxBodyBytes
+		DefaultMaxBodyBytes = 8
+		t.Cleanup(func() { DefaultMaxBodyBytes = prev })
+
+		var v M
+		req := httptest.NewRequest("POST", "/decode", strings.NewReader(`{"name":"sam"}`))
+
+		err := Decode(req, &v)
+
+		var herr Error
+		if !errors.As(err, &herr) || herr.Status != http.StatusRequestEntityTooLarge {
+			t.Fatalf("expected request entity too large Error, got %v", err)
+		}
+	})
+}
+
+func TestDecodeLimitedChunked(t *testing.T) {
+	// A reader without Len, so the request has no Content-Length, like a chunked upload.
+	body := &countingReader{ReadCloser: io.NopCloser(io.MultiReader(
+		strings.NewReader(`{"name":"`),
+		strings.NewReader(strings.Repeat("a", 1<<20)),
+		strings.NewReader(`"}`),
+	))}
+	req := httptest.NewRequest("POST", "/decode", body)
+	req.TransferEncoding = []string{"chunked"}
+	if req.ContentLength != -1 {
+		t.Fatalf("expected unknown Content-Length, got %d", req.ContentLength)
+	}
+
+	var v M
+	err := DecodeLimited(req, &v, 1024)
+
+	var herr Error
+	if !errors.As(err, &herr) || herr.Status != http.StatusRequestEntityTooLarge {
+		t.Fatalf("expected request entity too large Error, got %v", err)
+	}
+	if body.n >= 1<<20 {
+		t.Fatalf("expected decoding to stop at the limit, read %d bytes", body.n)
+	}
+}
+
+func TestDecodeErrorMessages(t *testing.T) {
+	type user struct {
+		Name string `json:"name"`
+		Age  int    `json:"age"`
+	}
+
+	tests := []struct {
+		name    string
+		body    string
+		wantMsg string
+	}{
+		{
+			name:    "type mismatch",
+			body:    `{"name":"sam","age":"ten"}`,
+			wantMsg: "invalid value at /age: expected number, got string",
+		},
+		{
+			name:    "syntax error",
+			body:    `{"name":"sam",}`,
+			wantMsg: "malformed JSON at byte offset 15: invalid character '}' looking for beginning of object key string",
+		},
+		{
+			name:    "truncated body",
+			body:    `{"name":"sam"`,
+			wantMsg: "request body is not complete JSON",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var v user
+			err := Decode(httptest.NewRequest("POST", "/decode", strings.NewReader(tt.body)), &v)
+
+			var herr Error
+			if !errors.As(err, &herr) || herr.Status != http.StatusBadRequest {
+				t.Fatalf("expected bad request Error, got %v", err)
+			}
+			if herr.Err.Error() != tt.wantMsg {
+				t.Fatalf("expected message %q, got %q", tt.wantMsg, herr.Err.Error())
+			}
+		})
+	}
+}
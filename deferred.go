@@ -0,0 +1,69 @@
+package httpwr
+
+import (
+	"bytes"
+	"net/http"
+)
+
+// Deferred gives h a ResponseWriter buffering the whole response.
+// The buffered response is sent only if h returns nil, otherwise it is discarded,
+// headers included, and the error is returned to be rendered by the error handler.
+// It prevents half written success responses when h fails after starting to write.
+// Since the response is held in memory, do not use it for large or streamed responses.
+func Deferred(h HandlerFunc) HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		bw := &bufferedWriter{header: make(http.Header), parent: w}
+
+		if err := h(bw, r); err != nil {
+			return err
+		}
+
+		dst := w.Header()
+		for k, v := range bw.header {
+			dst[k] = v
+		}
+
+		w.WriteHeader(bw.status())
+		_, err := w.Write(bw.body.Bytes())
+
+		return err
+	}
+}
+
+// bufferedWriter is a http.ResponseWriter keeping the response in memory.
+type bufferedWriter struct {
+	header http.Header
+	code   int
+	body   bytes.Buffer
+	// parent is the writer the response is sent to, so unwrapTo can find
+	// the request state it carries. It is not exposed with Unwrap,
+	// flushing through it would send the response before it is complete.
+	parent http.ResponseWriter
+}
+
+func (bw *bufferedWriter) Header() http.Header {
+	return bw.header
+}
+
+func (bw *bufferedWriter) WriteHeader(status int) {
+	if bw.code == 0 {
+		bw.code = status
+	}
+}
+
+func (bw *bufferedWriter) Write(b []byte) (int, error) {
+	if bw.code == 0 {
+		bw.code = http.StatusOK
+	}
+
+	return bw.body.Write(b)
+}
+
+// status returns the status written by the handler, http.StatusOK if there is none.
+func (bw *bufferedWriter) status() int {
+	if bw.code == 0 {
+		return http.StatusOK
+	}
+
+	return bw.code
+}
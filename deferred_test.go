@@ -0,0 +1,69 @@
+package httpwr
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestDeferred(t *testing.T) {
+	t.Run("error discards the partial response", func(t *testing.T) {
+		h := F(Deferred(func(w http.ResponseWriter, r *http.Request) error {
+			w.Header().Set("X-Partial", "yes")
+			_, _ = w.Write([]byte(`{"items":[1,2,`))
+			return Wrap(http.StatusBadGateway, errors.New("upstream failed"))
+		}))
+
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+
+		if w.Code != http.StatusBadGateway {
+			t.Fatalf("expected http status bad gateway, got %d", w.Code)
+		}
+		if w.Body.String() != `{"status":502,"error":"upstream failed"}`+"\n" {
+			t.Fatalf("unexpected body %q", w.Body.String())
+		}
+		if w.Header().Get("X-Partial") != "" {
+			t.Fatalf("expected the buffered headers to be discarded")
+		}
+	})
+
+	t.Run("success flushes the response", func(t *testing.T) {
+		h := F(Deferred(func(w http.ResponseWriter, r *http.Request) error {
+			return OK(w, http.StatusCreated, CreatedMsg)
+		}))
+
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest("POST", "/", nil))
+
+		if w.Code != http.StatusCreated {
+			t.Fatalf("expected http status created, got %d", w.Code)
+		}
+		if w.Body.String() != `{"status":201,"msg":"Created"}`+"\n" {
+			t.Fatalf("unexpected body %q", w.Body.String())
+		}
+		if got := w.Header().Get("Content-Type"); got != "application/json" {
+			t.Fatalf("expected Content-Type %q, got %q", "application/json", got)
+		}
+	})
+
+	t.Run("default data reaches the buffered response", func(t *testing.T) {
+		SetDefaultData(func(r *http.Request) M {
+			return M{"request_id": "abc"}
+		})
+		t.Cleanup(func() { SetDefaultData(nil) })
+
+		h := F(Deferred(func(w http.ResponseWriter, r *http.Request) error {
+			return OKWithData(w, http.StatusOK, OKMsg, M{"name": "gopher"})
+		}))
+
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+
+		want := `{"status":200,"msg":"OK","data":{"name":"gopher","request_id":"abc"}}` + "\n"
+		if w.Body.String() != want {
+			t.Fatalf("expected body %q, got %q", want, w.Body.String())
+		}
+	})
+}
@@ -0,0 +1,111 @@
+package httpwr
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+)
+
+// ErrShuttingDown is returned to requests that arrive while the server is draining.
+var ErrShuttingDown = errors.New("server is shutting down")
+
+// Drain tracks the in-flight requests for a graceful shutdown.
+// The middleware counts the requests going through it.
+// Calling wait starts draining: new requests are rejected with http.StatusServiceUnavailable,
+// and wait blocks until the in-flight requests finish or ctx is done.
+func Drain() (middleware func(http.Handler) http.Handler, wait func(context.Context) error) {
+	d := &drainer{idle: make(chan struct{})}
+	return d.middleware, d.wait
+}
+
+type drainer struct {
+	mu       sync.Mutex
+	inflight int
+	draining bool
+	idle     chan struct{}
+	once     sync.Once
+}
+
+func (d *drainer) middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		d.mu.Lock()
+		if d.draining {
+			d.mu.Unlock()
+			w.Header().Set("Connection", "close")
+			DefaultErrorHandler(w, http.StatusServiceUnavailable, ErrShuttingDown)
+			return
+		}
+		d.inflight++
+		d.mu.Unlock()
+
+		defer d.done()
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (d *drainer) done() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.inflight--
+	if d.draining && d.inflight == 0 {
+		d.once.Do(func() { close(d.idle) })
+	}
+}
+
+func (d *drainer) wait(ctx context.Context) error {
+	d.mu.Lock()
+	d.draining = true
+	if d.inflight == 0 {
+		d.once.Do(func() { close(d.idle) })
+	}
+	d.mu.Unlock()
+
+	select {
+	case <-d.idle:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Tracker tracks the in-flight requests going through its Middleware,
+// so a graceful shutdown can wait for them to finish.
+// Unlike Drain, it never rejects requests, it is meant to be used
+// with http.Server.Shutdown, which stops accepting them:
+//
+//	_ = srv.Shutdown(ctx)
+//	_ = tracker.Wait(ctx)
+//
+// The zero value is ready to use. A Tracker must not be copied after first use.
+type Tracker struct {
+	wg sync.WaitGroup
+}
+
+// Middleware counts the requests until their handler returns.
+func (t *Tracker) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.wg.Add(1)
+		defer t.wg.Done()
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// Wait blocks until the in-flight requests finish, or returns the error of ctx when it is done.
+// Requests must not arrive while it waits, call it once the server stopped accepting them.
+func (t *Tracker) Wait(ctx context.Context) error {
+	idle := make(chan struct{})
+	go func() {
+		t.wg.Wait()
+		close(idle)
+	}()
+
+	select {
+	case <-idle:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
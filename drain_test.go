@@ -0,0 +1,145 @@
+package httpwr
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestDrain(t *testing.T) {
+	mw, wait := Drain()
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	h := mw(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/slow" {
+			close(started)
+			<-release
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	slow := make(chan int)
+	go func() {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest("GET", "/slow", nil))
+		slow <- w.Code
+	}()
+	<-started
+
+	waited := make(chan error)
+	go func() {
+		waited <- wait(context.Background())
+	}()
+
+	// wait until draining started, so new requests are rejected
+	deadline := time.Now().Add(time.Second)
+	for {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest("GET", "/fast", nil))
+		if w.Code == http.StatusServiceUnavailable {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expected http status %d, got %d", http.StatusServiceUnavailable, w.Code)
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	select {
+	case err := <-waited:
+		t.Fatalf("wait returned before the slow request finished: %v", err)
+	default:
+	}
+
+	close(release)
+	if code := <-slow; code != http.StatusOK {
+		t.Fatalf("expected http status ok, got %d", code)
+	}
+	if err := <-waited; err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+}
+
+func TestDrainContextExpired(t *testing.T) {
+	mw, wait := Drain()
+
+	release := make(chan struct{})
+	started := make(chan struct{})
+	h := mw(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+	}))
+	go h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/slow", nil))
+	<-started
+	defer close(release)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	if err := wait(ctx); err != context.DeadlineExceeded {
+		t.Fatalf("expected %v, got %v", context.DeadlineExceeded, err)
+	}
+}
+
+func TestTracker(t *testing.T) {
+	var tracker Tracker
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	h := tracker.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	slow := make(chan int)
+	go func() {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest("GET", "/slow", nil))
+		slow <- w.Code
+	}()
+	<-started
+
+	waited := make(chan error)
+	go func() {
+		waited <- tracker.Wait(context.Background())
+	}()
+
+	select {
+	case err := <-waited:
+		t.Fatalf("wait returned before the slow request finished: %v", err)
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	close(release)
+	if code := <-slow; code != http.StatusOK {
+		t.Fatalf("expected http status ok, got %d", code)
+	}
+	if err := <-waited; err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+}
+
+func TestTrackerContextExpired(t *testing.T) {
+	var tracker Tracker
+
+	release := make(chan struct{})
+	started := make(chan struct{})
+	h := tracker.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+	}))
+	go h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/slow", nil))
+	<-started
+	defer close(release)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	if err := tracker.Wait(ctx); err != context.DeadlineExceeded {
+		t.Fatalf("expected %v, got %v", context.DeadlineExceeded, err)
+	}
+}
@@ -0,0 +1,141 @@
+package httpwr
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrStreamingUnsupported is returned by NewEventStream when the writer can not be flushed.
+var ErrStreamingUnsupported = errors.New("streaming unsupported")
+
+// EventStreamOptions configures an EventStream.
+type EventStreamOptions struct {
+	// Heartbeat is the interval between the `: ping` comments sent to keep an idle
+	// stream alive behind proxies closing quiet connections. Zero disables it.
+	Heartbeat time.Duration
+}
+
+// EventStream sends server-sent events to the client.
+// Close it before the handler returns.
+type EventStream struct {
+	mu sync.Mutex
+	w  http.ResponseWriter
+	rc *http.ResponseController
+
+	stop    chan struct{}
+	stopped chan struct{}
+	once    sync.Once
+}
+
+// NewEventStream starts a stream of server-sent events, sending the headers right away.
+// If opts.Heartbeat is set, a comment is sent at that interval until the request context
+// is done or the stream is closed.
+// It returns ErrStreamingUnsupported, wrapped with http.StatusInternalServerError,
+// if w can not be flushed, nothing is written in that case.
+func NewEventStream(w http.ResponseWriter, r *http.Request, opts EventStreamOptions) (*EventStream, error) {
+	h := w.Header()
+	prev := h.Clone()
+	h.Set("Content-Type", "text/event-stream")
+	h.Set("Cache-Control", "no-cache")
+	h.Set("Connection", "keep-alive")
+	// The events must reach the client as soon as they are sent.
+	h.Del("Content-Encoding")
+
+	// Flushing sends the headers with http.StatusOK, or reports that w can not be flushed,
+	// even behind a StatusRecorder, without writing anything.
+	rc := http.NewResponseController(w)
+	if err := rc.Flush(); err != nil {
+		for k := range h {
+			delete(h, k)
+		}
+		for k, v := range prev {
+			h[k] = v
+		}
+
+		if errors.Is(err, http.ErrNotSupported) {
+			err = ErrStreamingUnsupported
+		}
+		return nil, Wrap(http.StatusInternalServerError, err)
+	}
+
+	s := &EventStream{
+		w:       w,
+		rc:      rc,
+		stop:    make(chan struct{}),
+		stopped: make(chan struct{}),
+	}
+
+	if opts.Heartbeat <= 0 {
+		close(s.stopped)
+		return s, nil
+	}
+
+	go s.heartbeat(r, opts.Heartbeat)
+
+	return s, nil
+}
+
+// heartbeat sends a comment every interval until the request context is done
+// or the stream is closed.
+func (s *EventStream) heartbeat(r *http.Request, interval time.Duration) {
+	defer close(s.stopped)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-s.stop:
+			return
+		case <-ticker.C:
+			if err := s.write(": ping\n\n"); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// Send sends data encoded as JSON as an event of the given type.
+// An empty event sends a message event, the default type.
+func (s *EventStream) Send(event string, data any) error {
+	bts, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	var sb strings.Builder
+	if event != "" {
+		sb.WriteString("event: ")
+		sb.WriteString(event)
+		sb.WriteByte('\n')
+	}
+	sb.WriteString("data: ")
+	sb.Write(bts)
+	sb.WriteString("\n\n")
+
+	return s.write(sb.String())
+}
+
+func (s *EventStream) write(msg string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, err := s.w.Write([]byte(msg)); err != nil {
+		return err
+	}
+
+	return s.rc.Flush()
+}
+
+// Close stops the heartbeat and waits for it to return,
+// so nothing is written after the handler returns.
+func (s *EventStream) Close() {
+	s.once.Do(func() { close(s.stop) })
+	<-s.stopped
+}
@@ -0,0 +1,98 @@
+package httpwr
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEventStream(t *testing.T) {
+	w := httptest.NewRecorder()
+	s, err := NewEventStream(w, httptest.NewRequest("GET", "/events", nil), EventStreamOptions{})
+	if err != nil {
+		t.Fatalf("got error: %v", err)
+	}
+
+	_ = s.Send("user", M{"id": 1})
+	_ = s.Send("", "hello")
+	s.Close()
+
+	if got := w.Header().Get("Content-Type"); got != "text/event-stream" {
+		t.Fatalf("expected Content-Type %q, got %q", "text/event-stream", got)
+	}
+
+	want := "event: user\ndata: {\"id\":1}\n\ndata: \"hello\"\n\n"
+	if got := w.Body.String(); got != want {
+		t.Fatalf("expected body %q, got %q", want, got)
+	}
+}
+
+func TestEventStreamHeartbeat(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/events", nil).WithContext(ctx)
+
+	s, err := NewEventStream(w, req, EventStreamOptions{Heartbeat: 5 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("got error: %v", err)
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for {
+		s.mu.Lock()
+		body := w.Body.String()
+		s.mu.Unlock()
+
+		if strings.Contains(body, ": ping\n\n") {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expected a heartbeat, got %q", body)
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	cancel()
+
+	select {
+	case <-s.stopped:
+	case <-time.After(time.Second):
+		t.Fatalf("expected the heartbeat to stop when the context is done")
+	}
+
+	s.Close()
+}
+
+func TestEventStreamUnsupported(t *testing.T) {
+	var w struct{ http.ResponseWriter }
+	w.ResponseWriter = httptest.NewRecorder()
+
+	_, err := NewEventStream(w, httptest.NewRequest("GET", "/events", nil), EventStreamOptions{})
+	if !errors.Is(err, ErrStreamingUnsupported) {
+		t.Fatalf("expected ErrStreamingUnsupported, got %v", err)
+	}
+}
+
+func TestEventStreamUnsupportedThroughF(t *testing.T) {
+	var w struct{ http.ResponseWriter }
+	w.ResponseWriter = httptest.NewRecorder()
+
+	var err error
+	F(func(w http.ResponseWriter, r *http.Request) error {
+		_, err = NewEventStream(w, r, EventStreamOptions{})
+		return nil
+	}).ServeHTTP(w, httptest.NewRequest("GET", "/events", nil))
+
+	if !errors.Is(err, ErrStreamingUnsupported) {
+		t.Fatalf("expected ErrStreamingUnsupported, got %v", err)
+	}
+	if got := w.Header().Get("Content-Type"); got == "text/event-stream" {
+		t.Fatalf("expected the headers not to be set")
+	}
+}
@@ -0,0 +1,34 @@
+package httpwr
+
+import (
+	"context"
+	"errors"
+	"net/http"
+)
+
+// Flags stores the feature flags returned by resolve in the request context,
+// so handlers can check them with FlagEnabled or RequireFlag.
+func Flags(resolve func(*http.Request) map[string]bool) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := context.WithValue(r.Context(), flagsKey, resolve(r))
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// FlagEnabled reports whether the named flag is enabled in ctx.
+func FlagEnabled(ctx context.Context, name string) bool {
+	flags, _ := ctx.Value(flagsKey).(map[string]bool)
+	return flags[name]
+}
+
+// RequireFlag returns an Error with http.StatusNotFound if the named flag is disabled,
+// so gated endpoints look like they do not exist. It returns nil otherwise.
+func RequireFlag(ctx context.Context, name string) error {
+	if FlagEnabled(ctx, name) {
+		return nil
+	}
+
+	return Wrap(http.StatusNotFound, errors.New("not found"))
+}
@@ -0,0 +1,38 @@
+package httpwr
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRequireFlag(t *testing.T) {
+	h := Flags(func(r *http.Request) map[string]bool {
+		return map[string]bool{"beta": r.Header.Get("X-Beta") == "1"}
+	})(F(func(w http.ResponseWriter, r *http.Request) error {
+		if err := RequireFlag(r.Context(), "beta"); err != nil {
+			return err
+		}
+		return OK(w, http.StatusOK, OKMsg)
+	}))
+
+	t.Run("off", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest("GET", "/beta", nil))
+
+		if w.Code != http.StatusNotFound {
+			t.Fatalf("expected http status %d, got %d", http.StatusNotFound, w.Code)
+		}
+	})
+
+	t.Run("on", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/beta", nil)
+		req.Header.Set("X-Beta", "1")
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected http status ok, got %d", w.Code)
+		}
+	})
+}
@@ -1,3 +1,5 @@
 module github.com/samuelsih/httpwr
 
 go 1.20
+
+require golang.org/x/time v0.5.0
@@ -0,0 +1,58 @@
+package httpwr
+
+import (
+	"context"
+	"net/http"
+	"sync"
+)
+
+// Health returns a health check handler, usually mounted on /healthz.
+// The checks run concurrently with the request context.
+// If all of them pass, it sends http.StatusOK with `{"status":"ok"}`,
+// otherwise it sends http.StatusServiceUnavailable with the result of every check.
+func Health(checks map[string]func(context.Context) error) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var (
+			mu      sync.Mutex
+			wg      sync.WaitGroup
+			results = make(map[string]string, len(checks))
+			failed  bool
+		)
+
+		for name, check := range checks {
+			wg.Add(1)
+			go func(name string, check func(context.Context) error) {
+				defer wg.Done()
+
+				result := "ok"
+				err := check(r.Context())
+				if err != nil {
+					result = err.Error()
+				}
+
+				mu.Lock()
+				defer mu.Unlock()
+				results[name] = result
+				if err != nil {
+					failed = true
+				}
+			}(name, check)
+		}
+		wg.Wait()
+
+		type response struct {
+			Status string            `json:"status"`
+			Checks map[string]string `json:"checks,omitempty"`
+		}
+
+		if !failed {
+			_ = writeJSON(w, http.StatusOK, response{Status: "ok"})
+			return
+		}
+
+		_ = writeJSON(w, http.StatusServiceUnavailable, response{
+			Status: "unavailable",
+			Checks: results,
+		})
+	})
+}
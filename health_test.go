@@ -0,0 +1,66 @@
+package httpwr
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHealth(t *testing.T) {
+	ok := func(context.Context) error { return nil }
+	down := func(context.Context) error { return errors.New("connection refused") }
+
+	type response struct {
+		Status string            `json:"status"`
+		Checks map[string]string `json:"checks"`
+	}
+
+	serve := func(checks map[string]func(context.Context) error) (int, response) {
+		w := httptest.NewRecorder()
+		Health(checks).ServeHTTP(w, httptest.NewRequest("GET", "/healthz", nil))
+
+		var resp response
+		if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+			t.Fatalf("got error: %v", err)
+		}
+		return w.Code, resp
+	}
+
+	t.Run("all passing", func(t *testing.T) {
+		code, resp := serve(map[string]func(context.Context) error{"db": ok, "cache": ok})
+
+		if code != http.StatusOK {
+			t.Fatalf("expected http status ok, got %d", code)
+		}
+		if resp.Status != "ok" {
+			t.Fatalf("expected status %q, got %q", "ok", resp.Status)
+		}
+	})
+
+	t.Run("one failing", func(t *testing.T) {
+		code, resp := serve(map[string]func(context.Context) error{"db": ok, "cache": down})
+
+		if code != http.StatusServiceUnavailable {
+			t.Fatalf("expected http status %d, got %d", http.StatusServiceUnavailable, code)
+		}
+		if resp.Checks["db"] != "ok" {
+			t.Fatalf("expected db check %q, got %q", "ok", resp.Checks["db"])
+		}
+		if resp.Checks["cache"] != "connection refused" {
+			t.Fatalf("expected cache check %q, got %q", "connection refused", resp.Checks["cache"])
+		}
+	})
+
+	t.Run("failing with ok message", func(t *testing.T) {
+		code, _ := serve(map[string]func(context.Context) error{
+			"db": func(context.Context) error { return errors.New("ok") },
+		})
+
+		if code != http.StatusServiceUnavailable {
+			t.Fatalf("expected http status %d, got %d", http.StatusServiceUnavailable, code)
+		}
+	})
+}
@@ -1,10 +1,16 @@
 package httpwr
 
 import (
+	"bytes"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
 )
 
 const (
@@ -21,13 +27,69 @@ var (
 	ErrForbidden           = errors.New("forbidden")
 )
 
+// statusMessages are the default messages set by SetStatusMessages.
+var statusMessages map[int]string
+
+// SetStatusMessages sets the default messages, keyed by status, sent by OK
+// and DefaultErrorHandler when they are given an empty message.
+// The statuses without a message keep the standard text of the status, like "Not Found".
+// Pass nil to only use the standard texts, which is the default.
+// Call it before serving requests, it is not safe for concurrent use.
+func SetStatusMessages(messages map[int]string) {
+	statusMessages = make(map[int]string, len(messages))
+	for status, msg := range messages {
+		statusMessages[status] = msg
+	}
+}
+
+// statusMessage returns the default message of the status.
+func statusMessage(status int) string {
+	if msg, ok := statusMessages[status]; ok {
+		return msg
+	}
+
+	return http.StatusText(status)
+}
+
 // M is a map type with key string and value any.
 type M map[string]any
 
+// Merge copies the keys of src that are not present in m into m and returns it.
+// Keys already in m are never overwritten. A nil m is treated as empty,
+// so it is always safe to call Merge on a nil M.
+func (m M) Merge(src M) M {
+	if len(src) == 0 {
+		return m
+	}
+
+	if m == nil {
+		m = make(M, len(src))
+	}
+
+	for k, v := range src {
+		if _, ok := m[k]; !ok {
+			m[k] = v
+		}
+	}
+
+	return m
+}
+
 // Error is a HTTP error with an underlying error and a status code.
 type Error struct {
 	Status int   `json:"status"`
 	Err    error `json:"error"`
+
+	// Severity is the level the error should be logged at.
+	// If zero, it is inferred from the status, see SeverityOf.
+	Severity Severity `json:"-"`
+
+	// header is set by WrapWithHeader.
+	// Like stack, it is behind a pointer so Error stays comparable.
+	header *http.Header
+
+	// stack is captured by WrapTrace.
+	stack *[]uintptr
 }
 
 // Error() implements the error interface.
@@ -36,16 +98,29 @@ func (e Error) Error() string {
 }
 
 // Is conforms with errors.Is.
+// An empty Error{} target matches any Error, so it can be used as a type probe.
+// A target with a status only matches an Error with the same status,
+// like errors.Is(err, Error{Status: http.StatusNotFound}).
 func (e Error) Is(err error) bool {
-	switch err.(type) {
+	switch target := err.(type) {
 	case Error:
-		return true
+		return target.Status == 0 || target.Status == e.Status
 	default:
-		return errors.Is(e.Err, err)
+		// errors.Is reaches e.Err through Unwrap.
+		return false
 	}
 }
 
+// Unwrap returns the underlying error, so errors.As can reach
+// the errors wrapped with %w by Errorf.
+func (e Error) Unwrap() error {
+	return e.Err
+}
+
 // Wrap a given error with the given status.
+// The status always wins over the usual status of the error, so a sentinel can be
+// sent with another status, like Wrap(http.StatusNotFound, ErrForbidden),
+// while errors.Is(err, ErrForbidden) still holds.
 // Returns nil if the given error is nil.
 func Wrap(status int, err error) error {
 	if err == nil {
@@ -58,6 +133,75 @@ func Wrap(status int, err error) error {
 	}
 }
 
+// WrapWithHeader is like Wrap, but the given headers are also sent with the error response.
+// Returns nil if the given error is nil.
+func WrapWithHeader(status int, err error, h http.Header) error {
+	if err == nil {
+		return nil
+	}
+
+	return Error{
+		Err:    err,
+		Status: status,
+		header: &h,
+	}
+}
+
+// Header returns the headers set by WrapWithHeader, copied to the response
+// before the status is written, like WWW-Authenticate for http.StatusUnauthorized.
+// It returns nil if the error was not created by WrapWithHeader.
+func (e Error) Header() http.Header {
+	if e.header == nil {
+		return nil
+	}
+
+	return *e.header
+}
+
+// WrapSeverity is like Wrap, but the error is logged at the given severity
+// instead of the one inferred from the status.
+// Returns nil if the given error is nil.
+func WrapSeverity(status int, sev Severity, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	return Error{
+		Err:      err,
+		Status:   status,
+		Severity: sev,
+	}
+}
+
+// WrapTrace is like Wrap, but it also captures the stack trace of the caller.
+// The stack trace is never sent to the client, use StackTrace to retrieve it when logging.
+func WrapTrace(status int, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var pcs [32]uintptr
+	n := runtime.Callers(2, pcs[:])
+	stack := pcs[:n:n]
+
+	return Error{
+		Err:    err,
+		Status: status,
+		stack:  &stack,
+	}
+}
+
+// StackTrace returns the program counters captured by WrapTrace.
+// Use runtime.CallersFrames to resolve them into frames.
+// It returns nil if the error was not created by WrapTrace.
+func (e Error) StackTrace() []uintptr {
+	if e.stack == nil {
+		return nil
+	}
+
+	return *e.stack
+}
+
 // Errorf creates a new error and wraps it with the given status
 func Errorf(status int, format string, args ...any) error {
 	return Wrap(status, fmt.Errorf(format, args...))
@@ -80,75 +224,450 @@ func (f HandlerFunc) ServeHTTP(w http.ResponseWriter, r *http.Request) error {
 // ErrorHandler handles an error.
 type ErrorHandler func(w http.ResponseWriter, status int, err error)
 
+// ErrorHandlerFunc handles an error like ErrorHandler, but it also gets the request.
+// Unlike ErrorHandler, which gets the underlying error of an Error, err is the Error itself,
+// so its stack trace and headers are available. Use errors.Is or errors.As to inspect err.
+type ErrorHandlerFunc func(w http.ResponseWriter, r *http.Request, status int, err error)
+
+// AdaptErrorHandler converts eh into an ErrorHandlerFunc ignoring the request,
+// so an ErrorHandler can be used where an ErrorHandlerFunc is expected.
+// eh gets the underlying error of an Error, like with NewWithHandler.
+func AdaptErrorHandler(eh ErrorHandler) ErrorHandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request, status int, err error) {
+		eh(w, status, underlyingError(err))
+	}
+}
+
 // DefaultErrorHandler is the default error handler.
 // It converts the error to JSON and prints writes it to the response.
+// An empty message is replaced with the default message of the status, see SetStatusMessages.
+// The Fields of a ValidationError are sent in the fields key.
+// Invalid UTF-8 in the message is always replaced with U+FFFD.
 func DefaultErrorHandler(w http.ResponseWriter, status int, err error) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(status)
+	msg := err.Error()
+	if msg == "" {
+		msg = statusMessage(status)
+	}
 
-	_ = json.NewEncoder(w).Encode(errorResponse{
-		Status: status,
-		Err:    err.Error(),
-	})
+	resp := errorResponse{
+		Status: bodyStatus(status),
+		Err:    localize(w, status, strings.ToValidUTF8(msg, "\uFFFD")),
+	}
+
+	var verr *ValidationError
+	if errors.As(err, &verr) {
+		resp.Fields = verr.Fields
+	}
+
+	_ = writeJSON(w, status, resp)
+}
+
+// NegotiatingErrorHandler is like DefaultErrorHandler, but the error is sent as text/plain,
+// like `404 user not found`, when the Accept header of the request prefers it over JSON,
+// for clients like CLI tools. Use it with NewWithHandlerCtx.
+func NegotiatingErrorHandler(w http.ResponseWriter, r *http.Request, status int, err error) {
+	addVary(w.Header(), "Accept")
+	if !prefersText(r.Header.Get("Accept")) {
+		DefaultErrorHandler(w, status, err)
+		return
+	}
 
+	msg := localize(w, status, strings.ToValidUTF8(err.Error(), "\uFFFD"))
+	_ = writeBody(w, status, "text/plain; charset=utf-8", []byte(strconv.Itoa(status)+" "+msg+"\n"))
+}
+
+// prefersText reports whether the Accept header ranks plain text above JSON.
+func prefersText(accept string) bool {
+	if accept == "" {
+		return false
+	}
+
+	return acceptQuality(accept, "text/plain") > acceptQuality(accept, "application/json")
+}
+
+// WriteError renders err like the handlers returned by New do, with DefaultErrorHandler.
+// An Error is rendered with its status and headers, any other error
+// with http.StatusInternalServerError. Nothing is written if err is nil.
+// Use it to render an error caught locally in a handler.
+func WriteError(w http.ResponseWriter, err error) {
+	if err == nil {
+		return
+	}
+
+	handleError(w, err, DefaultErrorHandler)
+}
+
+// SafeError returns an ErrorHandler that renders the message of the errors
+// matching one of allowed with errors.Is, like DefaultErrorHandler.
+// Any other error is rendered with a generic message for its status,
+// so internal details are not leaked to the client.
+func SafeError(allowed ...error) ErrorHandler {
+	return func(w http.ResponseWriter, status int, err error) {
+		for _, target := range allowed {
+			if errors.Is(err, target) {
+				DefaultErrorHandler(w, status, err)
+				return
+			}
+		}
+
+		DefaultErrorHandler(w, status, genericError(status))
+	}
+}
+
+// genericError returns an error with the standard text of the status.
+func genericError(status int) error {
+	text := http.StatusText(status)
+	if text == "" {
+		return ErrInternalServerError
+	}
+
+	return errors.New(strings.ToLower(text))
 }
 
 // OK converts the status and message to JSON and sends it to user.
 // Also, it will write the header based on the status.
+// An empty msg is replaced with the default message of the status, see SetStatusMessages,
+// which is the standard text of the status by default, like "Not Found".
+// An invalid UTF-8 msg is handled as set by SetRejectInvalidUTF8.
+// Encoding errors are ignored, use RespondOK to get them.
 func OK(w http.ResponseWriter, status int, msg string) error {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(status)
+	if err := RespondOK(w, status, msg); errors.Is(err, ErrInvalidUTF8) {
+		return err
+	}
+
+	return nil
+}
+
+// RespondOK is like OK, but it returns the error of encoding or writing the response,
+// so it is meaningful as the final return of a handler.
+func RespondOK(w http.ResponseWriter, status int, msg string) error {
+	if msg == "" {
+		msg = statusMessage(status)
+	}
+
+	msg, err := checkUTF8(msg)
+	if err != nil {
+		return err
+	}
 
 	type r struct {
-		Status int    `json:"status"`
+		Status int    `json:"status,omitempty"`
 		Msg    string `json:"msg"`
 	}
 
-	_ = json.NewEncoder(w).Encode(r{
-		Status: status,
-		Msg:    msg,
+	return writeJSON(w, status, r{
+		Status: bodyStatus(status),
+		Msg:    localize(w, status, msg),
 	})
+}
 
-	return nil
+// Status sends the status with its standard text as the message, like OK(w, status, "").
+func Status(w http.ResponseWriter, status int) error {
+	return OK(w, status, "")
 }
 
 // OK converts the status, message and custom data you want to JSON.
 // Also, it will write the header based on the status.
+// A nil M is written as an empty object instead of null, like M{},
+// so clients can always expect an object. The data key is never omitted.
+// If data is an M, the data set by SetDefaultData is merged into it.
+// An invalid UTF-8 msg is handled as set by SetRejectInvalidUTF8.
+// Encoding errors are ignored, use RespondData to get them.
 func OKWithData[T any](w http.ResponseWriter, status int, msg string, data T) error {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(status)
+	if err := RespondData(w, status, msg, data); errors.Is(err, ErrInvalidUTF8) {
+		return err
+	}
+
+	return nil
+}
+
+// RespondData is like OKWithData, but it returns the error of encoding or writing
+// the response, so it is meaningful as the final return of a handler.
+func RespondData[T any](w http.ResponseWriter, status int, msg string, data T) error {
+	msg, err := checkUTF8(msg)
+	if err != nil {
+		return err
+	}
+
+	if m, ok := any(data).(M); ok {
+		data = any(withDefaultData(w, m)).(T)
+	}
 
 	type r struct {
-		Status int    `json:"status"`
+		Status int    `json:"status,omitempty"`
 		Msg    string `json:"msg"`
 		Data   T      `json:"data"`
 	}
 
-	_ = json.NewEncoder(w).Encode(r{
-		Status: status,
-		Msg:    msg,
+	return writeJSON(w, status, r{
+		Status: bodyStatus(status),
+		Msg:    localize(w, status, msg),
 		Data:   data,
 	})
+}
 
+// Done returns nil. Use it as the final return of a handler that already wrote
+// its response, to make the intent explicit, like
+//
+//	httpwr.SetContentLanguage(w, "fr")
+//	w.WriteHeader(http.StatusNoContent)
+//	return httpwr.Done()
+func Done() error {
 	return nil
 }
 
+// JSONRaw converts v to JSON and sends it as the whole body, without the
+// status and message envelope of OK and OKWithData, like a top level array.
+// The error is returned if v can not be encoded, nothing is written in that case.
+func JSONRaw(w http.ResponseWriter, status int, v any) error {
+	return writeJSON(w, status, v)
+}
+
+// defaultData returns the data added to every OKWithData response, nil adds nothing.
+var defaultData func(r *http.Request) M
+
+// SetDefaultData sets the function returning the data added to the data of every
+// OKWithData response, like a request ID or the server time.
+// The keys given to OKWithData are never overwritten.
+// It is called with the request when a handler returned by New, F and the like
+// writes an M with OKWithData, so it costs nothing for the other responses.
+// Pass nil to add nothing, which is the default.
+// Call it before serving requests, it is not safe for concurrent use.
+func SetDefaultData(fn func(r *http.Request) M) {
+	defaultData = fn
+}
+
+// withDefaultData returns m with the default data of the handler serving w.
+// m is copied before merging, so the map of the caller is never modified,
+// and it is returned as is when there is nothing to merge.
+// A nil m is returned as an empty M.
+func withDefaultData(w http.ResponseWriter, m M) M {
+	if m == nil {
+		m = M{}
+	}
+
+	rec, ok := unwrapTo[*StatusRecorder](w)
+	if !ok || rec.defaults == nil {
+		return m
+	}
+
+	defaults := rec.defaults()
+	if len(defaults) == 0 {
+		return m
+	}
+
+	merged := make(M, len(m)+len(defaults))
+	for k, v := range m {
+		merged[k] = v
+	}
+
+	return merged.Merge(defaults)
+}
+
+// unwrapTo returns the first writer of type T in the chain of writers
+// wrapped by w with an Unwrap method, starting with w itself.
+// The writer given to a Deferred handler is followed to the writer it buffers for.
+func unwrapTo[T http.ResponseWriter](w http.ResponseWriter) (T, bool) {
+	for {
+		if v, ok := w.(T); ok {
+			return v, true
+		}
+
+		if bw, ok := w.(*bufferedWriter); ok {
+			w = bw.parent
+			continue
+		}
+
+		u, ok := w.(interface{ Unwrap() http.ResponseWriter })
+		if !ok {
+			var zero T
+			return zero, false
+		}
+		w = u.Unwrap()
+	}
+}
+
+// ErrInvalidUTF8 is returned by the response helpers for a message that is not valid UTF-8,
+// when SetRejectInvalidUTF8 is enabled.
+var ErrInvalidUTF8 = errors.New("response message is not valid UTF-8")
+
+// rejectInvalidUTF8 makes the response helpers reject invalid UTF-8 messages.
+var rejectInvalidUTF8 bool
+
+// SetRejectInvalidUTF8 controls how OK and OKWithData handle a message that is not valid UTF-8,
+// like one built from raw database bytes.
+// By default, every invalid byte sequence is replaced with U+FFFD.
+// If enabled, nothing is written and ErrInvalidUTF8 is returned,
+// wrapped with http.StatusInternalServerError.
+// Call it before serving requests, it is not safe for concurrent use.
+func SetRejectInvalidUTF8(reject bool) {
+	rejectInvalidUTF8 = reject
+}
+
+// checkUTF8 returns msg with the invalid byte sequences replaced with U+FFFD,
+// or an error if they are rejected.
+func checkUTF8(msg string) (string, error) {
+	if utf8.ValidString(msg) {
+		return msg, nil
+	}
+
+	if rejectInvalidUTF8 {
+		return "", Wrap(http.StatusInternalServerError, ErrInvalidUTF8)
+	}
+
+	return strings.ToValidUTF8(msg, "\uFFFD"), nil
+}
+
+// excludeStatusFromBody makes the response helpers omit the status field.
+var excludeStatusFromBody bool
+
+// SetIncludeStatusInBody controls whether the status field is sent in the JSON envelopes
+// of the response helpers, like OK, OKWithData and DefaultErrorHandler, which is the default.
+// If disabled, the status field is omitted from every envelope, and the status
+// is only known from the response status code.
+// Call it before serving requests, it is not safe for concurrent use.
+func SetIncludeStatusInBody(include bool) {
+	excludeStatusFromBody = !include
+}
+
+// bodyStatus returns the status to send in the JSON body, 0 omits it.
+func bodyStatus(status int) int {
+	if excludeStatusFromBody {
+		return 0
+	}
+
+	return status
+}
+
+// prettyJSON makes the response helpers indent their JSON output.
+var prettyJSON bool
+
+// SetPrettyJSON toggles indentation of the JSON written by the response helpers.
+// It is off by default, so responses stay compact in production.
+// Call it before serving requests, it is not safe for concurrent use.
+func SetPrettyJSON(enabled bool) {
+	prettyJSON = enabled
+}
+
+// jsonContentType is the Content-Type of the JSON responses.
+var jsonContentType = "application/json"
+
+// SetDefaultContentType sets the Content-Type of the JSON responses, like OK, OKWithData
+// and JSONRaw, to a variant of JSON like `application/json; charset=utf-8`
+// or a vendor media type like `application/vnd.myapi+json`.
+// An empty ct restores the default, `application/json`.
+// Call it before serving requests, it is not safe for concurrent use.
+func SetDefaultContentType(ct string) {
+	if ct == "" {
+		ct = "application/json"
+	}
+
+	jsonContentType = ct
+}
+
+// writeJSON writes v as JSON with the given status.
+// The body is encoded before anything is written, so an encoding error
+// never leaves a partially written response.
+func writeJSON(w http.ResponseWriter, status int, v any) error {
+	buf := getJSONBuffer()
+	defer putJSONBuffer(buf)
+
+	if err := buf.encode(v); err != nil {
+		return err
+	}
+
+	return writeBody(w, status, jsonContentType, buf.Bytes())
+}
+
+// maxPooledBuffer is the capacity above which buffers are not put back into the pool,
+// so a few huge responses do not keep their memory alive.
+const maxPooledBuffer = 64 << 10
+
+var jsonBufferPool = sync.Pool{
+	New: func() any {
+		buf := new(jsonBuffer)
+		buf.enc = json.NewEncoder(&buf.Buffer)
+		return buf
+	},
+}
+
+// jsonBuffer is a buffer with an encoder writing into it,
+// so both are reused together.
+type jsonBuffer struct {
+	bytes.Buffer
+	enc *json.Encoder
+}
+
+// encode encodes v followed by a newline into the buffer.
+// On error, nothing is written to the buffer.
+func (buf *jsonBuffer) encode(v any) error {
+	if prettyJSON {
+		buf.enc.SetIndent("", "  ")
+	} else {
+		buf.enc.SetIndent("", "")
+	}
+
+	return buf.enc.Encode(v)
+}
+
+func getJSONBuffer() *jsonBuffer {
+	return jsonBufferPool.Get().(*jsonBuffer)
+}
+
+func putJSONBuffer(buf *jsonBuffer) {
+	if buf.Cap() > maxPooledBuffer {
+		return
+	}
+
+	buf.Reset()
+	jsonBufferPool.Put(buf)
+}
+
+// writeBody writes the body with the given status, content type and length.
+// The length is not set when a Content-Encoding is set, since a compressing writer
+// changes the length of the body sent to the client.
+func writeBody(w http.ResponseWriter, status int, contentType string, body []byte) error {
+	h := w.Header()
+	h.Set("Content-Type", contentType)
+
+	if !bodyAllowed(status) {
+		w.WriteHeader(status)
+		return nil
+	}
+
+	if h.Get("Content-Encoding") == "" {
+		h.Set("Content-Length", strconv.Itoa(len(body)))
+	}
+	w.WriteHeader(status)
+
+	_, err := w.Write(body)
+	return err
+}
+
+// bodyAllowed reports whether a response with the given status can have a body.
+func bodyAllowed(status int) bool {
+	switch {
+	case status >= 100 && status <= 199:
+		return false
+	case status == http.StatusNoContent, status == http.StatusNotModified:
+		return false
+	}
+
+	return true
+}
+
 // NewWithHandler wraps a given http.Handler and returns a http.Handler.
 // You can also customize how the error is handled.
+// If the handler already wrote the response before returning the error,
+// the error is not handled, so the response is not corrupted.
 func NewWithHandler(next Handler, eh ErrorHandler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		err := next.ServeHTTP(w, r)
-		if err == nil {
-			return
-		}
+	return CustomHandlerFn(next.ServeHTTP, eh)
+}
 
-		var herr Error
-		if errors.As(err, &herr) {
-			eh(w, herr.Status, herr.Err)
-		} else {
-			eh(w, http.StatusInternalServerError, err)
-		}
-	})
+// NewWithHandlerCtx is like NewWithHandler, but the error handler also gets the request,
+// to log the error with the request context or to negotiate the response format.
+func NewWithHandlerCtx(next Handler, eh ErrorHandlerFunc) http.Handler {
+	return customHandlerFnCtx(next.ServeHTTP, eh)
 }
 
 // New wraps a given http.Handler and returns a http.Handler.
@@ -174,21 +693,35 @@ func F(next HandlerFunc) http.Handler {
 	return New(next)
 }
 
+// Must wraps a handler that can not fail and return a http.Handler,
+// so it can be mixed with the error returning handlers.
+func Must(h func(http.ResponseWriter, *http.Request)) http.Handler {
+	return F(func(w http.ResponseWriter, r *http.Request) error {
+		h(w, r)
+		return nil
+	})
+}
+
 // CustomHandlerFn converts the httpwr.HandlerFunc into http.HandlerFunc with custom ErrorHandler.
 // Use this if you want to return http.HandlerFunc instead of http.Handler.
 func CustomHandlerFn(fn HandlerFunc, eh ErrorHandler) http.HandlerFunc {
+	return customHandlerFnCtx(fn, AdaptErrorHandler(eh))
+}
+
+func customHandlerFnCtx(fn HandlerFunc, eh ErrorHandlerFunc) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		err := fn(w, r)
-		if err == nil {
-			return
+		rec := NewStatusRecorder(w)
+		if fn := defaultData; fn != nil {
+			rec.defaults = func() M { return fn(r) }
 		}
 
-		var herr Error
-		if errors.As(err, &herr) {
-			eh(w, herr.Status, herr.Err)
-		} else {
-			eh(w, http.StatusInternalServerError, err)
+		err := fn(rec, r)
+		if err == nil || rec.Written() || rec.Hijacked() {
+			return
 		}
+
+		status, err := prepareError(rec, err)
+		eh(rec, r, status, err)
 	}
 }
 
@@ -198,7 +731,41 @@ func HandlerFn(fn HandlerFunc) http.HandlerFunc {
 	return CustomHandlerFn(fn, DefaultErrorHandler)
 }
 
+// handleError renders err with eh.
+// An Error is rendered with its status and headers, any other error with http.StatusInternalServerError.
+func handleError(w http.ResponseWriter, err error, eh ErrorHandler) {
+	status, err := prepareError(w, err)
+	eh(w, status, underlyingError(err))
+}
+
+// underlyingError returns the underlying error of an Error returned by prepareError,
+// or err as is.
+func underlyingError(err error) error {
+	if herr, ok := err.(Error); ok {
+		return herr.Err
+	}
+
+	return err
+}
+
+// prepareError returns the status and the error to render for err,
+// and copies the headers of an Error to the response.
+func prepareError(w http.ResponseWriter, err error) (int, error) {
+	var herr Error
+	if !errors.As(err, &herr) {
+		return http.StatusInternalServerError, err
+	}
+
+	h := w.Header()
+	for k, v := range herr.Header() {
+		h[k] = append([]string(nil), v...)
+	}
+
+	return herr.Status, herr
+}
+
 type errorResponse struct {
-	Status int    `json:"status"`
-	Err    string `json:"error"`
+	Status int               `json:"status,omitempty"`
+	Err    string            `json:"error"`
+	Fields map[string]string `json:"fields,omitempty"`
 }
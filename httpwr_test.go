@@ -1,13 +1,18 @@
 package httpwr
 
 import (
+	"bytes"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
+	"log"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"runtime"
 	"strings"
+	"sync"
 	"testing"
 )
 
@@ -366,6 +371,1028 @@ func TestHeaderJSON(t *testing.T) {
 	}
 }
 
+func TestOKWithNilData(t *testing.T) {
+	req := httptest.NewRequest("GET", "/test", nil)
+	w := httptest.NewRecorder()
+	F(func(w http.ResponseWriter, r *http.Request) error {
+		var data M
+		return OKWithData(w, http.StatusOK, OKMsg, data)
+	}).ServeHTTP(w, req)
+	resp := w.Result()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected http status ok, got %d", resp.StatusCode)
+	}
+
+	bts, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("got error: %v", err)
+	}
+	if !strings.Contains(string(bts), `"data":{}`) {
+		t.Fatalf("%q does not contain %q", string(bts), `"data":{}`)
+	}
+}
+
+func TestMerge(t *testing.T) {
+	t.Run("nil receiver", func(t *testing.T) {
+		var m M
+		got := m.Merge(M{"a": 1})
+		if got["a"] != 1 {
+			t.Fatalf("expected merged key, got %v", got)
+		}
+	})
+
+	t.Run("nil source", func(t *testing.T) {
+		var m M
+		if got := m.Merge(nil); len(got) != 0 {
+			t.Fatalf("expected empty map, got %v", got)
+		}
+	})
+
+	t.Run("existing keys win", func(t *testing.T) {
+		got := M{"a": 1}.Merge(M{"a": 2, "b": 3})
+		if got["a"] != 1 || got["b"] != 3 {
+			t.Fatalf("unexpected merge result: %v", got)
+		}
+	})
+}
+
+func TestMust(t *testing.T) {
+	var calls int
+	header := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("X-Chain", "yes")
+			next.ServeHTTP(w, r)
+		})
+	}
+
+	mux := http.NewServeMux()
+	chain := Chain(header)
+	mux.Handle("/must", chain(Must(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusAccepted)
+	})))
+	mux.Handle("/err", chain(F(func(w http.ResponseWriter, r *http.Request) error {
+		return Errorf(http.StatusTeapot, "short and stout")
+	})))
+
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest("GET", "/must", nil))
+	if calls != 1 {
+		t.Fatalf("expected handler to be called once, got %d", calls)
+	}
+	if w.Code != http.StatusAccepted {
+		t.Fatalf("expected http status %d, got %d", http.StatusAccepted, w.Code)
+	}
+	if w.Header().Get("X-Chain") != "yes" {
+		t.Fatalf("expected middleware to run")
+	}
+
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest("GET", "/err", nil))
+	if w.Code != http.StatusTeapot {
+		t.Fatalf("expected http status %d, got %d", http.StatusTeapot, w.Code)
+	}
+	if w.Header().Get("X-Chain") != "yes" {
+		t.Fatalf("expected middleware to run")
+	}
+}
+
+func TestWrapTrace(t *testing.T) {
+	err := WrapTrace(http.StatusInternalServerError, io.EOF)
+
+	if !errors.Is(err, io.EOF) {
+		t.Fatalf("underlying error should be io.EOF")
+	}
+
+	var herr Error
+	if !errors.As(err, &herr) {
+		t.Fatalf("expected Error, got %v", err)
+	}
+
+	pcs := herr.StackTrace()
+	if len(pcs) == 0 {
+		t.Fatalf("expected stack trace, got none")
+	}
+
+	frame, _ := runtime.CallersFrames(pcs).Next()
+	if !strings.HasSuffix(frame.Function, "TestWrapTrace") {
+		t.Fatalf("expected first frame to be the caller, got %s", frame.Function)
+	}
+
+	if Wrap(http.StatusBadRequest, io.EOF).(Error).StackTrace() != nil {
+		t.Fatalf("expected no stack trace from Wrap")
+	}
+	if WrapTrace(http.StatusBadRequest, nil) != nil {
+		t.Fatalf("expected nil error")
+	}
+}
+
+func TestWrapTraceNotSentToClient(t *testing.T) {
+	req := httptest.NewRequest("GET", "/trace", nil)
+	w := httptest.NewRecorder()
+	F(func(w http.ResponseWriter, r *http.Request) error {
+		return WrapTrace(http.StatusInternalServerError, errors.New("boom"))
+	}).ServeHTTP(w, req)
+
+	bts, err := io.ReadAll(w.Result().Body)
+	if err != nil {
+		t.Fatalf("got error: %v", err)
+	}
+	if strings.Contains(string(bts), "TestWrapTrace") || strings.Contains(string(bts), ".go") {
+		t.Fatalf("%q should not contain the stack trace", string(bts))
+	}
+}
+
+func TestSetPrettyJSON(t *testing.T) {
+	serve := func() string {
+		req := httptest.NewRequest("GET", "/pretty", nil)
+		w := httptest.NewRecorder()
+		F(func(w http.ResponseWriter, r *http.Request) error {
+			return OKWithData(w, http.StatusOK, OKMsg, M{"a": 1})
+		}).ServeHTTP(w, req)
+		return w.Body.String()
+	}
+
+	compact := serve()
+	if want := `{"status":200,"msg":"OK","data":{"a":1}}` + "\n"; compact != want {
+		t.Fatalf("expected %q, got %q", want, compact)
+	}
+
+	SetPrettyJSON(true)
+	t.Cleanup(func() { SetPrettyJSON(false) })
+
+	pretty := serve()
+	want := "{\n  \"status\": 200,\n  \"msg\": \"OK\",\n  \"data\": {\n    \"a\": 1\n  }\n}\n"
+	if pretty != want {
+		t.Fatalf("expected %q, got %q", want, pretty)
+	}
+}
+
+func TestWriteJSONEncodeError(t *testing.T) {
+	w := httptest.NewRecorder()
+
+	err := writeJSON(w, http.StatusOK, M{"ch": make(chan int)})
+	if err == nil {
+		t.Fatalf("expected error, got none")
+	}
+	if w.Body.Len() != 0 {
+		t.Fatalf("expected nothing written, got %q", w.Body.String())
+	}
+
+	// the buffer used for the failed encoding must be clean when reused.
+	w = httptest.NewRecorder()
+	if err := writeJSON(w, http.StatusOK, M{"a": 1}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if got := w.Body.String(); got != `{"a":1}`+"\n" {
+		t.Fatalf("unexpected body %q", got)
+	}
+}
+
+func TestOKWithDataConcurrent(t *testing.T) {
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			w := httptest.NewRecorder()
+			_ = OKWithData(w, http.StatusOK, OKMsg, M{"i": i})
+
+			want := fmt.Sprintf(`"data":{"i":%d}`, i)
+			if !strings.Contains(w.Body.String(), want) {
+				t.Errorf("%q does not contain %q", w.Body.String(), want)
+			}
+		}(i)
+	}
+	wg.Wait()
+}
+
+func BenchmarkOKWithData(b *testing.B) {
+	data := M{"id": 1, "name": "sam", "tags": []string{"a", "b"}}
+	w := httptest.NewRecorder()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		w.Body.Reset()
+		_ = OKWithData(w, http.StatusOK, OKMsg, data)
+	}
+}
+
+func BenchmarkServeNoError(b *testing.B) {
+	h := F(func(w http.ResponseWriter, r *http.Request) error {
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	})
+	req := httptest.NewRequest("GET", "/", nil)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		h.ServeHTTP(httptest.NewRecorder(), req)
+	}
+}
+
+func BenchmarkServeError(b *testing.B) {
+	err := Errorf(http.StatusNotFound, "user not found")
+	h := F(func(w http.ResponseWriter, r *http.Request) error {
+		return err
+	})
+	req := httptest.NewRequest("GET", "/", nil)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		h.ServeHTTP(httptest.NewRecorder(), req)
+	}
+}
+
+func TestWrapWithHeader(t *testing.T) {
+	challenge := `Bearer realm="api"`
+	req := httptest.NewRequest("GET", "/secret", nil)
+	w := httptest.NewRecorder()
+	F(func(w http.ResponseWriter, r *http.Request) error {
+		return WrapWithHeader(http.StatusUnauthorized, ErrUnauthorized, http.Header{
+			"Www-Authenticate": {challenge},
+		})
+	}).ServeHTTP(w, req)
+	resp := w.Result()
+
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("expected http status %d, got %d", http.StatusUnauthorized, resp.StatusCode)
+	}
+	if got := resp.Header.Get("WWW-Authenticate"); got != challenge {
+		t.Fatalf("expected WWW-Authenticate %q, got %q", challenge, got)
+	}
+
+	if WrapWithHeader(http.StatusUnauthorized, nil, nil) != nil {
+		t.Fatalf("expected nil error")
+	}
+}
+
+func TestErrorComparable(t *testing.T) {
+	if (Error{}) != (Error{}) {
+		t.Fatalf("expected empty errors to be equal")
+	}
+
+	var err error = WrapWithHeader(http.StatusUnauthorized, ErrUnauthorized, http.Header{
+		"Www-Authenticate": {"Basic"},
+	})
+	if err == ErrUnauthorized {
+		t.Fatalf("expected the Error not to equal its underlying error")
+	}
+	if err != err {
+		t.Fatalf("expected the Error to equal itself")
+	}
+}
+
+func TestErrorAfterWrite(t *testing.T) {
+	var logs bytes.Buffer
+	srv := httptest.NewUnstartedServer(F(func(w http.ResponseWriter, r *http.Request) error {
+		_ = OK(w, http.StatusOK, "written")
+		return errors.New("too late")
+	}))
+	srv.Config.ErrorLog = log.New(&logs, "", 0)
+	srv.Start()
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL)
+	if err != nil {
+		t.Fatalf("got error: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected http status ok, got %d", resp.StatusCode)
+	}
+
+	bts, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("got error: %v", err)
+	}
+	if string(bts) != `{"status":200,"msg":"written"}`+"\n" {
+		t.Fatalf("expected the original body intact, got %q", string(bts))
+	}
+
+	if strings.Contains(logs.String(), "superfluous") {
+		t.Fatalf("unexpected warning: %s", logs.String())
+	}
+}
+
+func TestSafeError(t *testing.T) {
+	errNotFound := errors.New("user not found")
+	eh := SafeError(errNotFound)
+
+	tests := []struct {
+		name    string
+		err     error
+		status  int
+		wantMsg string
+	}{
+		{name: "allowed", err: fmt.Errorf("get user: %w", errNotFound), status: http.StatusNotFound, wantMsg: "get user: user not found"},
+		{name: "masked", err: errors.New("pq: connection refused"), status: http.StatusBadGateway, wantMsg: "bad gateway"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			eh(w, tt.status, tt.err)
+
+			if w.Code != tt.status {
+				t.Fatalf("expected http status %d, got %d", tt.status, w.Code)
+			}
+
+			var body errorResponse
+			if err := json.NewDecoder(w.Body).Decode(&body); err != nil {
+				t.Fatalf("got error: %v", err)
+			}
+			if body.Err != tt.wantMsg {
+				t.Fatalf("expected error message %q, got %q", tt.wantMsg, body.Err)
+			}
+		})
+	}
+}
+
+func TestWriteError(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name:       "error with status",
+			err:        Errorf(http.StatusConflict, "user already exists"),
+			wantStatus: http.StatusConflict,
+			wantBody:   `{"status":409,"error":"user already exists"}`,
+		},
+		{
+			name:       "plain error",
+			err:        errors.New("boom"),
+			wantStatus: http.StatusInternalServerError,
+			wantBody:   `{"status":500,"error":"boom"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			WriteError(w, tt.err)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("expected http status %d, got %d", tt.wantStatus, w.Code)
+			}
+			if got := strings.TrimSpace(w.Body.String()); got != tt.wantBody {
+				t.Fatalf("expected body %s, got %s", tt.wantBody, got)
+			}
+		})
+	}
+}
+
+func TestErrorIsStatus(t *testing.T) {
+	err := fmt.Errorf("get user: %w", Wrap(http.StatusNotFound, ErrBadRequest))
+
+	tests := []struct {
+		name   string
+		target error
+		want   bool
+	}{
+		{name: "type probe", target: Error{}, want: true},
+		{name: "same status", target: Error{Status: http.StatusNotFound}, want: true},
+		{name: "other status", target: Error{Status: http.StatusConflict}, want: false},
+		{name: "wrapped error", target: ErrBadRequest, want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := errors.Is(err, tt.target); got != tt.want {
+				t.Fatalf("expected errors.Is to be %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestOKEmptyMessage(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   int
+		msg      string
+		wantBody string
+	}{
+		{name: "not found", status: http.StatusNotFound, wantBody: `{"status":404,"msg":"Not Found"}`},
+		{name: "accepted", status: http.StatusAccepted, wantBody: `{"status":202,"msg":"Accepted"}`},
+		{name: "explicit message", status: http.StatusNotFound, msg: "user not found", wantBody: `{"status":404,"msg":"user not found"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			_ = OK(w, tt.status, tt.msg)
+
+			if got := strings.TrimSpace(w.Body.String()); got != tt.wantBody {
+				t.Fatalf("expected body %s, got %s", tt.wantBody, got)
+			}
+		})
+	}
+}
+
+func TestStatus(t *testing.T) {
+	w := httptest.NewRecorder()
+	_ = Status(w, http.StatusTeapot)
+
+	if w.Code != http.StatusTeapot {
+		t.Fatalf("expected http status %d, got %d", http.StatusTeapot, w.Code)
+	}
+	if got := strings.TrimSpace(w.Body.String()); got != `{"status":418,"msg":"I'm a teapot"}` {
+		t.Fatalf("unexpected body %s", got)
+	}
+}
+
+func TestSetDefaultData(t *testing.T) {
+	SetDefaultData(func(r *http.Request) M {
+		return M{"request_id": r.Header.Get(RequestIDHeader), "region": "eu"}
+	})
+	t.Cleanup(func() { SetDefaultData(nil) })
+
+	data := M{"id": 1, "region": "us"}
+	h := F(func(w http.ResponseWriter, r *http.Request) error {
+		return OKWithData(w, http.StatusOK, OKMsg, data)
+	})
+
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set(RequestIDHeader, "abc-123")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+
+	want := `{"status":200,"msg":"OK","data":{"id":1,"region":"us","request_id":"abc-123"}}`
+	if got := strings.TrimSpace(w.Body.String()); got != want {
+		t.Fatalf("expected body %s, got %s", want, got)
+	}
+	if len(data) != 2 {
+		t.Fatalf("expected the caller data not to be modified, got %v", data)
+	}
+}
+
+func TestSetDefaultDataOnlyCalledForM(t *testing.T) {
+	var calls int
+	SetDefaultData(func(r *http.Request) M {
+		calls++
+		return M{"region": "eu"}
+	})
+	t.Cleanup(func() { SetDefaultData(nil) })
+
+	h := F(func(w http.ResponseWriter, r *http.Request) error {
+		if r.URL.Path == "/data" {
+			return OKWithData(w, http.StatusOK, OKMsg, M{"id": 1})
+		}
+		return OK(w, http.StatusOK, OKMsg)
+	})
+
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+	if calls != 0 {
+		t.Fatalf("expected no call for a response without an M, got %d", calls)
+	}
+
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/data", nil))
+	if calls != 1 {
+		t.Fatalf("expected 1 call for a response with an M, got %d", calls)
+	}
+}
+
+func TestSetDefaultDataNil(t *testing.T) {
+	SetDefaultData(nil)
+
+	h := F(func(w http.ResponseWriter, r *http.Request) error {
+		return OKWithData(w, http.StatusOK, OKMsg, M{"id": 1})
+	})
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+
+	want := `{"status":200,"msg":"OK","data":{"id":1}}`
+	if got := strings.TrimSpace(w.Body.String()); got != want {
+		t.Fatalf("expected body %s, got %s", want, got)
+	}
+}
+
+func TestJSONRaw(t *testing.T) {
+	w := httptest.NewRecorder()
+	if err := JSONRaw(w, http.StatusOK, []M{{"id": 1}, {"id": 2}}); err != nil {
+		t.Fatalf("got error: %v", err)
+	}
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected http status ok, got %d", w.Code)
+	}
+	if got := w.Header().Get("Content-Type"); got != "application/json" {
+		t.Fatalf("expected Content-Type %q, got %q", "application/json", got)
+	}
+	if got := w.Body.String(); got != `[{"id":1},{"id":2}]`+"\n" {
+		t.Fatalf("unexpected body %q", got)
+	}
+}
+
+func TestErrorfWrapsChain(t *testing.T) {
+	pathErr := &os.PathError{Op: "open", Path: "config.json", Err: io.EOF}
+	err := Errorf(http.StatusInternalServerError, "load config: %w", pathErr)
+
+	if !errors.Is(err, io.EOF) {
+		t.Fatalf("expected %v to be io.EOF", err)
+	}
+
+	var target *os.PathError
+	if !errors.As(err, &target) || target != pathErr {
+		t.Fatalf("expected %v to be the *os.PathError", err)
+	}
+}
+
+func TestOKWithDataMaps(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     M
+		wantBody string
+	}{
+		{name: "nil map", data: nil, wantBody: `{"status":200,"msg":"OK","data":{}}`},
+		{name: "empty map", data: M{}, wantBody: `{"status":200,"msg":"OK","data":{}}`},
+		{name: "populated map", data: M{"id": 1}, wantBody: `{"status":200,"msg":"OK","data":{"id":1}}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			_ = OKWithData(w, http.StatusOK, OKMsg, tt.data)
+
+			if got := strings.TrimSpace(w.Body.String()); got != tt.wantBody {
+				t.Fatalf("expected body %s, got %s", tt.wantBody, got)
+			}
+		})
+	}
+}
+
+func TestContentLength(t *testing.T) {
+	srv := httptest.NewServer(F(func(w http.ResponseWriter, r *http.Request) error {
+		return OK(w, http.StatusOK, "hello")
+	}))
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL)
+	if err != nil {
+		t.Fatalf("got error: %v", err)
+	}
+	defer resp.Body.Close()
+
+	want := `{"status":200,"msg":"hello"}` + "\n"
+	if resp.ContentLength != int64(len(want)) {
+		t.Fatalf("expected Content-Length %d, got %d", len(want), resp.ContentLength)
+	}
+	if len(resp.TransferEncoding) != 0 {
+		t.Fatalf("expected no Transfer-Encoding, got %v", resp.TransferEncoding)
+	}
+}
+
+func TestContentLengthWithContentEncoding(t *testing.T) {
+	w := httptest.NewRecorder()
+	w.Header().Set("Content-Encoding", "gzip")
+	_ = OK(w, http.StatusOK, "hello")
+
+	if got := w.Header().Get("Content-Length"); got != "" {
+		t.Fatalf("expected no Content-Length with a Content-Encoding, got %q", got)
+	}
+}
+
+func TestSetDefaultContentType(t *testing.T) {
+	SetDefaultContentType("application/vnd.myapi+json")
+	t.Cleanup(func() { SetDefaultContentType("") })
+
+	tests := []struct {
+		name string
+		fn   func(w http.ResponseWriter) error
+	}{
+		{name: "OK", fn: func(w http.ResponseWriter) error { return OK(w, http.StatusOK, OKMsg) }},
+		{name: "OKWithData", fn: func(w http.ResponseWriter) error { return OKWithData(w, http.StatusOK, OKMsg, M{"id": 1}) }},
+		{name: "JSONRaw", fn: func(w http.ResponseWriter) error { return JSONRaw(w, http.StatusOK, []int{1}) }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			_ = tt.fn(w)
+
+			if got := w.Header().Get("Content-Type"); got != "application/vnd.myapi+json" {
+				t.Fatalf("expected Content-Type %q, got %q", "application/vnd.myapi+json", got)
+			}
+		})
+	}
+}
+
+func TestWrapSentinelOverride(t *testing.T) {
+	var returned error
+	h := F(func(w http.ResponseWriter, r *http.Request) error {
+		returned = Wrap(http.StatusNotFound, ErrForbidden)
+		return returned
+	})
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/documents/1", nil))
+
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("expected http status not found, got %d", w.Code)
+	}
+	if !errors.Is(returned, ErrForbidden) {
+		t.Fatalf("expected %v to still be ErrForbidden", returned)
+	}
+	if !errors.Is(returned, Error{Status: http.StatusNotFound}) {
+		t.Fatalf("expected %v to have the overridden status", returned)
+	}
+
+	for _, sentinel := range []error{ErrInternalServerError, ErrBadRequest, ErrUnauthorized, ErrForbidden} {
+		err := fmt.Errorf("handler: %w", Wrap(http.StatusTeapot, sentinel))
+
+		var herr Error
+		if !errors.As(err, &herr) || herr.Status != http.StatusTeapot {
+			t.Fatalf("expected the status of %v to be overridden, got %v", sentinel, err)
+		}
+		if !errors.Is(err, sentinel) {
+			t.Fatalf("expected %v to still be %v", err, sentinel)
+		}
+	}
+}
+
+func TestInvalidUTF8(t *testing.T) {
+	msg := "caf\xe9 \xff\xfe ok"
+
+	t.Run("replace", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		if err := OK(w, http.StatusOK, msg); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		want := `{"status":200,"msg":"caf` + "�" + ` ` + "�" + ` ok"}`
+		if got := strings.TrimSpace(w.Body.String()); got != want {
+			t.Fatalf("expected body %s, got %s", want, got)
+		}
+	})
+
+	t.Run("reject", func(t *testing.T) {
+		SetRejectInvalidUTF8(true)
+		t.Cleanup(func() { SetRejectInvalidUTF8(false) })
+
+		w := httptest.NewRecorder()
+		F(func(w http.ResponseWriter, r *http.Request) error {
+			return OKWithData(w, http.StatusOK, msg, M{"id": 1})
+		}).ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+
+		if w.Code != http.StatusInternalServerError {
+			t.Fatalf("expected http status internal server error, got %d", w.Code)
+		}
+		if got := strings.TrimSpace(w.Body.String()); got != `{"status":500,"error":"response message is not valid UTF-8"}` {
+			t.Fatalf("unexpected body %s", got)
+		}
+	})
+}
+
+func TestRespondFamily(t *testing.T) {
+	t.Run("encoding error is returned", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		err := RespondData(w, http.StatusOK, OKMsg, M{"ch": make(chan int)})
+
+		var typeErr *json.UnsupportedTypeError
+		if !errors.As(err, &typeErr) {
+			t.Fatalf("expected *json.UnsupportedTypeError, got %v", err)
+		}
+		if w.Body.Len() != 0 {
+			t.Fatalf("expected nothing to be written, got %q", w.Body.String())
+		}
+	})
+
+	t.Run("encoding error is rendered", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		F(func(w http.ResponseWriter, r *http.Request) error {
+			return RespondData(w, http.StatusOK, OKMsg, M{"ch": make(chan int)})
+		}).ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+
+		if w.Code != http.StatusInternalServerError {
+			t.Fatalf("expected http status internal server error, got %d", w.Code)
+		}
+	})
+
+	t.Run("success", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		if err := RespondOK(w, http.StatusCreated, CreatedMsg); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if got := strings.TrimSpace(w.Body.String()); got != `{"status":201,"msg":"Created"}` {
+			t.Fatalf("unexpected body %s", got)
+		}
+	})
+
+	t.Run("done", func(t *testing.T) {
+		if err := Done(); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+	})
+}
+
+func TestNegotiatingErrorHandler(t *testing.T) {
+	h := NewWithHandlerCtx(HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		return Errorf(http.StatusNotFound, "user not found")
+	}), NegotiatingErrorHandler)
+
+	tests := []struct {
+		accept      string
+		contentType string
+		body        string
+	}{
+		{accept: "text/plain", contentType: "text/plain; charset=utf-8", body: "404 user not found\n"},
+		{accept: "application/json", contentType: "application/json", body: `{"status":404,"error":"user not found"}` + "\n"},
+		{accept: "*/*", contentType: "application/json", body: `{"status":404,"error":"user not found"}` + "\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.accept, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/users/1", nil)
+			req.Header.Set("Accept", tt.accept)
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, req)
+
+			if w.Code != http.StatusNotFound {
+				t.Fatalf("expected http status not found, got %d", w.Code)
+			}
+			if got := w.Header().Get("Content-Type"); got != tt.contentType {
+				t.Fatalf("expected Content-Type %q, got %q", tt.contentType, got)
+			}
+			if got := w.Body.String(); got != tt.body {
+				t.Fatalf("expected body %q, got %q", tt.body, got)
+			}
+		})
+	}
+}
+
+func TestNewWithHandlerCtx(t *testing.T) {
+	var gotID string
+	eh := func(w http.ResponseWriter, r *http.Request, status int, err error) {
+		gotID = RequestIDFromContext(r.Context())
+		DefaultErrorHandler(w, status, err)
+	}
+
+	h := RequestID(NewWithHandlerCtx(HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		return Errorf(http.StatusConflict, "conflict")
+	}), eh))
+
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set(RequestIDHeader, "abc-123")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+
+	if w.Code != http.StatusConflict {
+		t.Fatalf("expected http status conflict, got %d", w.Code)
+	}
+	if gotID != "abc-123" {
+		t.Fatalf("expected the request to be passed to the error handler, got request id %q", gotID)
+	}
+}
+
+func TestAdaptErrorHandler(t *testing.T) {
+	var called bool
+	legacy := ErrorHandler(func(w http.ResponseWriter, status int, err error) {
+		called = true
+		DefaultErrorHandler(w, status, err)
+	})
+
+	h := NewWithHandlerCtx(HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		return Errorf(http.StatusTeapot, "short and stout")
+	}), AdaptErrorHandler(legacy))
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+
+	if !called {
+		t.Fatalf("expected the legacy error handler to be called")
+	}
+	if w.Code != http.StatusTeapot {
+		t.Fatalf("expected http status %d, got %d", http.StatusTeapot, w.Code)
+	}
+}
+
+func TestSetIncludeStatusInBody(t *testing.T) {
+	tests := []struct {
+		name    string
+		include bool
+		write   func(w http.ResponseWriter)
+		want    string
+	}{
+		{
+			name:    "ok by default",
+			include: true,
+			write:   func(w http.ResponseWriter) { _ = OK(w, http.StatusOK, OKMsg) },
+			want:    `{"status":200,"msg":"OK"}`,
+		},
+		{
+			name:  "ok without status",
+			write: func(w http.ResponseWriter) { _ = OK(w, http.StatusOK, OKMsg) },
+			want:  `{"msg":"OK"}`,
+		},
+		{
+			name:  "data without status",
+			write: func(w http.ResponseWriter) { _ = OKWithData(w, http.StatusOK, OKMsg, M{"id": 1}) },
+			want:  `{"msg":"OK","data":{"id":1}}`,
+		},
+		{
+			name:    "error by default",
+			include: true,
+			write:   func(w http.ResponseWriter) { DefaultErrorHandler(w, http.StatusNotFound, errors.New("user not found")) },
+			want:    `{"status":404,"error":"user not found"}`,
+		},
+		{
+			name:  "error without status",
+			write: func(w http.ResponseWriter) { DefaultErrorHandler(w, http.StatusNotFound, errors.New("user not found")) },
+			want:  `{"error":"user not found"}`,
+		},
+		{
+			name:  "empty without status",
+			write: func(w http.ResponseWriter) { _ = OKEmpty(w, http.StatusOK) },
+			want:  `{"data":[]}`,
+		},
+		{
+			name:  "unsupported media type without status",
+			write: func(w http.ResponseWriter) { _ = UnsupportedMediaType(w, "text/plain", "application/json") },
+			want:  `{"error":"unsupported media type \"text/plain\"","supported":["application/json"]}`,
+		},
+		{
+			name:  "method not allowed without status",
+			write: func(w http.ResponseWriter) { _ = MethodNotAllowed(w, http.MethodGet) },
+			want:  `{"error":"method not allowed"}`,
+		},
+		{
+			name: "links without status",
+			write: func(w http.ResponseWriter) {
+				_ = OKWithLinks(w, http.StatusOK, M{"id": 1}, map[string]string{"self": "/users/1"})
+			},
+			want: `{"data":{"id":1},"_links":{"self":"/users/1"}}`,
+		},
+		{
+			name: "paginated without status",
+			write: func(w http.ResponseWriter) {
+				_ = WritePaginated(w, http.StatusOK, Paginated[int]{Items: []int{1}, Page: 1, PerPage: 10, Total: 1})
+			},
+			want: `{"items":[1],"page":1,"per_page":10,"total":1,"total_pages":1}`,
+		},
+		{
+			name:  "job without status",
+			write: func(w http.ResponseWriter) { _ = JobStatus(w, JobRunning, 50, nil) },
+			want:  `{"state":"running","progress":50}`,
+		},
+		{
+			name: "debug error without status",
+			write: func(w http.ResponseWriter) {
+				DebugErrorHandler(w, httptest.NewRequest("GET", "/", nil), http.StatusNotFound, errors.New("user not found"))
+			},
+			want: `{"error":"user not found","trace":[{"message":"user not found","type":"*errors.errorString"}]}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			SetIncludeStatusInBody(tt.include)
+			t.Cleanup(func() { SetIncludeStatusInBody(true) })
+
+			w := httptest.NewRecorder()
+			tt.write(w)
+
+			if w.Body.String() != tt.want+"\n" {
+				t.Fatalf("expected body %q, got %q", tt.want+"\n", w.Body.String())
+			}
+		})
+	}
+}
+
+func TestSetStatusMessages(t *testing.T) {
+	SetStatusMessages(map[int]string{
+		http.StatusOK:       "all good",
+		http.StatusNotFound: "nothing here",
+	})
+	t.Cleanup(func() { SetStatusMessages(nil) })
+
+	tests := []struct {
+		name  string
+		write func(w http.ResponseWriter)
+		want  string
+	}{
+		{
+			name:  "ok",
+			write: func(w http.ResponseWriter) { _ = OK(w, http.StatusOK, "") },
+			want:  `{"status":200,"msg":"all good"}`,
+		},
+		{
+			name:  "error",
+			write: func(w http.ResponseWriter) { DefaultErrorHandler(w, http.StatusNotFound, errors.New("")) },
+			want:  `{"status":404,"error":"nothing here"}`,
+		},
+		{
+			name:  "explicit message",
+			write: func(w http.ResponseWriter) { _ = OK(w, http.StatusOK, "done") },
+			want:  `{"status":200,"msg":"done"}`,
+		},
+		{
+			name:  "standard text",
+			write: func(w http.ResponseWriter) { _ = Status(w, http.StatusAccepted) },
+			want:  `{"status":202,"msg":"Accepted"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			tt.write(w)
+
+			if w.Body.String() != tt.want+"\n" {
+				t.Fatalf("expected body %q, got %q", tt.want+"\n", w.Body.String())
+			}
+		})
+	}
+}
+
+func TestHijackedConnection(t *testing.T) {
+	var handled bool
+	var writeErr error
+	done := make(chan struct{})
+
+	h := NewWithHandler(HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		conn, buf, err := http.NewResponseController(w).Hijack()
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+
+		_, _ = buf.WriteString("HTTP/1.1 200 OK\r\nContent-Length: 2\r\nConnection: close\r\n\r\nhi")
+		_ = buf.Flush()
+
+		_, writeErr = w.Write([]byte("too late"))
+
+		return Errorf(http.StatusInternalServerError, "connection closed")
+	}), func(w http.ResponseWriter, status int, err error) {
+		handled = true
+		DefaultErrorHandler(w, status, err)
+	})
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer close(done)
+		h.ServeHTTP(w, r)
+	}))
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL)
+	if err != nil {
+		t.Fatalf("got error: %v", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("got error: %v", err)
+	}
+	<-done
+
+	if string(body) != "hi" {
+		t.Fatalf("expected body %q, got %q", "hi", string(body))
+	}
+	if !errors.Is(writeErr, http.ErrHijacked) {
+		t.Fatalf("expected %v, got %v", http.ErrHijacked, writeErr)
+	}
+	if handled {
+		t.Fatalf("expected the error handler not to be called on a hijacked connection")
+	}
+}
+
+func TestErrorHandlerGetsUnderlyingError(t *testing.T) {
+	var legacyErr, ctxErr error
+	fn := HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		return WrapTrace(http.StatusForbidden, ErrForbidden)
+	})
+
+	NewWithHandler(fn, func(w http.ResponseWriter, status int, err error) {
+		legacyErr = err
+	}).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+
+	NewWithHandlerCtx(fn, func(w http.ResponseWriter, r *http.Request, status int, err error) {
+		ctxErr = err
+	}).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+
+	if legacyErr != ErrForbidden {
+		t.Fatalf("expected the ErrorHandler to get %v, got %T", ErrForbidden, legacyErr)
+	}
+
+	herr, ok := ctxErr.(Error)
+	if !ok {
+		t.Fatalf("expected the ErrorHandlerFunc to get an Error, got %T", ctxErr)
+	}
+	if herr.StackTrace() == nil {
+		t.Fatalf("expected the stack trace to be available")
+	}
+}
+
 func TestHandlerFnWithUnknownError(t *testing.T) {
 	req := httptest.NewRequest("GET", "/hf", nil)
 	w := httptest.NewRecorder()
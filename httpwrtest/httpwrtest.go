@@ -0,0 +1,58 @@
+// Package httpwrtest provides helpers for testing handlers written with httpwr.
+package httpwrtest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/samuelsih/httpwr"
+)
+
+// AssertJSON serves req with h, and reports a test error if the response status
+// is not wantStatus or the JSON body is not equal to wantBody.
+// The bodies are compared after decoding, so the key order and formatting do not matter.
+func AssertJSON(t testing.TB, h http.Handler, req *http.Request, wantStatus int, wantBody httpwr.M) {
+	t.Helper()
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+
+	if w.Code != wantStatus {
+		t.Errorf("expected http status %d, got %d", wantStatus, w.Code)
+	}
+
+	var got map[string]any
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+		t.Errorf("body %q is not a JSON object: %v", w.Body.String(), err)
+		return
+	}
+
+	want, err := normalize(wantBody)
+	if err != nil {
+		t.Errorf("can not encode the expected body: %v", err)
+		return
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected body %v, got %v", want, got)
+	}
+}
+
+// normalize round-trips m through JSON, so its values have the same types
+// as a decoded body, like float64 for numbers.
+func normalize(m httpwr.M) (map[string]any, error) {
+	bts, err := json.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+
+	var out map[string]any
+	if err := json.Unmarshal(bts, &out); err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
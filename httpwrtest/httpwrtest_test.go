@@ -0,0 +1,49 @@
+package httpwrtest
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/samuelsih/httpwr"
+)
+
+// recordingTB records the failures instead of failing the test.
+type recordingTB struct {
+	testing.TB
+	errors []string
+}
+
+func (r *recordingTB) Helper() {}
+
+func (r *recordingTB) Errorf(format string, args ...any) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func TestAssertJSON(t *testing.T) {
+	h := httpwr.F(func(w http.ResponseWriter, r *http.Request) error {
+		return httpwr.OKWithData(w, http.StatusOK, httpwr.OKMsg, httpwr.M{"id": 1})
+	})
+
+	t.Run("pass", func(t *testing.T) {
+		AssertJSON(t, h, httptest.NewRequest("GET", "/", nil), http.StatusOK, httpwr.M{
+			"status": 200,
+			"msg":    httpwr.OKMsg,
+			"data":   httpwr.M{"id": 1},
+		})
+	})
+
+	t.Run("fail", func(t *testing.T) {
+		rec := &recordingTB{TB: t}
+		AssertJSON(rec, h, httptest.NewRequest("GET", "/", nil), http.StatusCreated, httpwr.M{
+			"status": 200,
+			"msg":    httpwr.OKMsg,
+			"data":   httpwr.M{"id": 2},
+		})
+
+		if len(rec.errors) != 2 {
+			t.Fatalf("expected status and body failures, got %v", rec.errors)
+		}
+	})
+}
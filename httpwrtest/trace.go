@@ -0,0 +1,31 @@
+package httpwrtest
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+)
+
+type depthKey struct{}
+
+// Trace returns a middleware that appends an "enter N" event to events before calling
+// the next handler and an "exit N" event after it returns, where N is the nesting depth
+// of the trace middleware, starting at 1 for the outermost one.
+// Put it between your middlewares to assert the order in which they run.
+// The events are not synchronized, so serve one request at a time.
+func Trace(events *[]string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			depth, _ := r.Context().Value(depthKey{}).(int)
+			depth++
+
+			*events = append(*events, fmt.Sprintf("enter %d", depth))
+			defer func() {
+				*events = append(*events, fmt.Sprintf("exit %d", depth))
+			}()
+
+			ctx := context.WithValue(r.Context(), depthKey{}, depth)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
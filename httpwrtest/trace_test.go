@@ -0,0 +1,25 @@
+package httpwrtest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/samuelsih/httpwr"
+)
+
+func TestTrace(t *testing.T) {
+	var events []string
+
+	h := httpwr.Chain(Trace(&events), Trace(&events))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		events = append(events, "handler")
+	}))
+
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+
+	want := []string{"enter 1", "enter 2", "handler", "exit 2", "exit 1"}
+	if !reflect.DeepEqual(events, want) {
+		t.Fatalf("expected events %v, got %v", want, events)
+	}
+}
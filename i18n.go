@@ -0,0 +1,163 @@
+package httpwr
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// SetContentLanguage sets the Content-Language of the response to lang,
+// and adds Accept-Language to the Vary header, so caches keep one copy per language.
+// Call it before writing the response.
+func SetContentLanguage(w http.ResponseWriter, lang string) {
+	h := w.Header()
+	h.Set("Content-Language", lang)
+	addVary(h, "Accept-Language")
+}
+
+// addVary adds value to the Vary header unless it is already listed.
+func addVary(h http.Header, value string) {
+	for _, line := range h.Values("Vary") {
+		for _, v := range strings.Split(line, ",") {
+			v = strings.TrimSpace(v)
+			if v == "*" || strings.EqualFold(v, value) {
+				return
+			}
+		}
+	}
+
+	h.Add("Vary", value)
+}
+
+// messageFunc resolves the localized messages, nil keeps the English ones.
+var messageFunc func(lang string, status int) string
+
+// SetMessageFunc sets the function resolving the message of a status in the language
+// preferred by the client, as found by the Localize middleware.
+// OK, OKWithData and DefaultErrorHandler use it when the message is just the standard
+// text of the status, like OKMsg or ErrBadRequest, specific messages are kept as is.
+// If fn returns an empty string, the English message is kept.
+// Pass nil to only use the English messages, which is the default.
+// Call it before serving requests, it is not safe for concurrent use.
+func SetMessageFunc(fn func(lang string, status int) string) {
+	messageFunc = fn
+}
+
+// Localize is a middleware that finds the language preferred by the client
+// from the Accept-Language header, so the response helpers can localize their messages
+// with the function set by SetMessageFunc.
+// Accept-Language is always added to the Vary header, and the language is set
+// with SetContentLanguage when a message is actually localized.
+func Localize(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		addVary(w.Header(), "Accept-Language")
+
+		lang := preferredLanguage(r.Header.Get("Accept-Language"))
+		if lang == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		next.ServeHTTP(&langWriter{ResponseWriter: w, lang: lang}, r)
+	})
+}
+
+// localize returns the message of status in the language of w
+// if msg is the standard text of status, otherwise msg is returned.
+// When the message is localized, its language is set with SetContentLanguage.
+func localize(w http.ResponseWriter, status int, msg string) string {
+	if messageFunc == nil || !strings.EqualFold(msg, http.StatusText(status)) {
+		return msg
+	}
+
+	lang := languageOf(w)
+	if lang == "" {
+		return msg
+	}
+
+	if localized := messageFunc(lang, status); localized != "" {
+		SetContentLanguage(w, lang)
+		return localized
+	}
+
+	return msg
+}
+
+// preferredLanguage returns the language with the highest quality
+// in an Accept-Language header, or an empty string if there is none.
+func preferredLanguage(header string) string {
+	var (
+		best    string
+		bestQ   = 0.0
+		entries = strings.Split(header, ",")
+	)
+
+	for _, entry := range entries {
+		tag, params, _ := strings.Cut(strings.TrimSpace(entry), ";")
+		tag = strings.TrimSpace(tag)
+		if tag == "" || tag == "*" {
+			continue
+		}
+
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+
+		if q > bestQ {
+			best, bestQ = tag, q
+		}
+	}
+
+	return best
+}
+
+// languageOf returns the language stored by Localize in w or the writers it wraps.
+func languageOf(w http.ResponseWriter) string {
+	if lw, ok := unwrapTo[*langWriter](w); ok {
+		return lw.lang
+	}
+
+	return ""
+}
+
+// langWriter carries the language preferred by the client to the response helpers.
+type langWriter struct {
+	http.ResponseWriter
+	lang string
+}
+
+// Flush implements http.Flusher if the underlying writer supports it.
+func (lw *langWriter) Flush() {
+	if f, ok := lw.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// FlushError flushes the underlying writer like http.ResponseController does,
+// so it returns http.ErrNotSupported if the underlying writer can not be flushed.
+func (lw *langWriter) FlushError() error {
+	return http.NewResponseController(lw.ResponseWriter).Flush()
+}
+
+// Hijack implements http.Hijacker if the underlying writer supports it.
+func (lw *langWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := lw.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, fmt.Errorf("httpwr: %T does not implement http.Hijacker", lw.ResponseWriter)
+	}
+
+	return h.Hijack()
+}
+
+// Unwrap returns the underlying http.ResponseWriter.
+func (lw *langWriter) Unwrap() http.ResponseWriter {
+	return lw.ResponseWriter
+}
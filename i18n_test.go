@@ -0,0 +1,145 @@
+package httpwr
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestSetContentLanguage(t *testing.T) {
+	w := httptest.NewRecorder()
+	w.Header().Set("Vary", "Origin")
+
+	SetContentLanguage(w, "fr")
+	SetContentLanguage(w, "fr")
+
+	if got := w.Header().Get("Content-Language"); got != "fr" {
+		t.Fatalf("expected Content-Language %q, got %q", "fr", got)
+	}
+
+	vary := w.Header().Values("Vary")
+	if len(vary) != 2 || vary[0] != "Origin" || vary[1] != "Accept-Language" {
+		t.Fatalf("expected Vary [Origin Accept-Language], got %v", vary)
+	}
+}
+
+func TestSetMessageFunc(t *testing.T) {
+	SetMessageFunc(func(lang string, status int) string {
+		if !strings.HasPrefix(lang, "fr") {
+			return ""
+		}
+
+		switch status {
+		case http.StatusOK:
+			return "D'accord"
+		case http.StatusBadRequest:
+			return "Requête invalide"
+		default:
+			return ""
+		}
+	})
+	t.Cleanup(func() { SetMessageFunc(nil) })
+
+	tests := []struct {
+		name       string
+		lang       string
+		err        error
+		wantStatus int
+		wantBody   string
+		wantLang   string
+	}{
+		{
+			name:       "ok in french",
+			lang:       "fr-CH, fr;q=0.9, en;q=0.8",
+			wantStatus: http.StatusOK,
+			wantBody:   `{"status":200,"msg":"D'accord"}`,
+			wantLang:   "fr-CH",
+		},
+		{
+			name:       "bad request in french",
+			lang:       "en;q=0.5, fr",
+			err:        Wrap(http.StatusBadRequest, ErrBadRequest),
+			wantStatus: http.StatusBadRequest,
+			wantBody:   `{"status":400,"error":"Requête invalide"}`,
+			wantLang:   "fr",
+		},
+		{
+			name:       "specific message is kept",
+			lang:       "fr",
+			err:        Errorf(http.StatusBadRequest, "name is required"),
+			wantStatus: http.StatusBadRequest,
+			wantBody:   `{"status":400,"error":"name is required"}`,
+		},
+		{
+			name:       "unsupported language",
+			lang:       "de",
+			wantStatus: http.StatusOK,
+			wantBody:   `{"status":200,"msg":"OK"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := Localize(F(func(w http.ResponseWriter, r *http.Request) error {
+				if tt.err != nil {
+					return tt.err
+				}
+				return OK(w, http.StatusOK, OKMsg)
+			}))
+
+			req := httptest.NewRequest("GET", "/", nil)
+			req.Header.Set("Accept-Language", tt.lang)
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("expected http status %d, got %d", tt.wantStatus, w.Code)
+			}
+
+			if got := strings.TrimSpace(w.Body.String()); got != tt.wantBody {
+				t.Fatalf("expected body %s, got %s", tt.wantBody, got)
+			}
+			if vary := w.Header().Get("Vary"); vary != "Accept-Language" {
+				t.Fatalf("expected Vary %q, got %q", "Accept-Language", vary)
+			}
+			if got := w.Header().Get("Content-Language"); got != tt.wantLang {
+				t.Fatalf("expected Content-Language %q, got %q", tt.wantLang, got)
+			}
+		})
+	}
+}
+
+func TestLocalizeWithoutMessageFunc(t *testing.T) {
+	h := Localize(F(func(w http.ResponseWriter, r *http.Request) error {
+		return OK(w, http.StatusOK, OKMsg)
+	}))
+
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("Accept-Language", "ja")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+
+	if got := w.Header().Get("Content-Language"); got != "" {
+		t.Fatalf("expected no Content-Language on an English body, got %q", got)
+	}
+	if vary := w.Header().Get("Vary"); vary != "Accept-Language" {
+		t.Fatalf("expected Vary %q, got %q", "Accept-Language", vary)
+	}
+}
+
+func TestPreferredLanguage(t *testing.T) {
+	tests := map[string]string{
+		"":                          "",
+		"fr":                        "fr",
+		"en;q=0.5, fr-CH, fr;q=0.9": "fr-CH",
+		"*, de;q=0.7":               "de",
+		"en;q=0, nl;q=bad":          "",
+	}
+
+	for header, want := range tests {
+		if got := preferredLanguage(header); got != want {
+			t.Fatalf("preferredLanguage(%q): expected %q, got %q", header, want, got)
+		}
+	}
+}
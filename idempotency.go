@@ -0,0 +1,227 @@
+package httpwr
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"strconv"
+	"sync"
+)
+
+// IdempotencyKeyHeader is the header carrying the idempotency key of a request.
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// ErrIdempotencyKeyInUse is sent when a request with the same idempotency key is in flight.
+var ErrIdempotencyKeyInUse = errors.New("a request with the same idempotency key is in progress")
+
+// ErrIdempotencyKeyReused is sent when an idempotency key is reused with another request body.
+var ErrIdempotencyKeyReused = errors.New("the idempotency key was used with another request body")
+
+// IdempotentResponse is a response stored by Idempotency.
+type IdempotentResponse struct {
+	Status int
+	Header http.Header
+	Body   []byte
+
+	// RequestHash is the SHA-256 of the body of the request, hex encoded,
+	// so the response is only replayed for the same body.
+	RequestHash string
+}
+
+// IdempotencyStore stores the responses of the requests with an idempotency key,
+// like Redis or an in-memory map. It must be safe for concurrent use.
+type IdempotencyStore interface {
+	// Get returns the response stored for key, false if there is none.
+	Get(key string) (IdempotentResponse, bool, error)
+
+	// Set stores the response for key.
+	Set(key string, resp IdempotentResponse) error
+}
+
+// Idempotency makes the requests with an Idempotency-Key header safe to retry,
+// like a POST charging a card.
+// The first response for a key is stored, and the following requests with the same key
+// get it replayed without calling the handler, with an Idempotent-Replayed header.
+//
+// The keys are scoped by the method and the path of the request, and by scope if not nil,
+// which returns the caller of the request, like the authenticated user ID,
+// so a client can never get the response stored for another one by reusing its key.
+// A request reusing a key with another body is never replayed,
+// it is rejected with http.StatusUnprocessableEntity.
+//
+// Server errors, with a status of 500 or above, are not stored, so they can be retried.
+// A request whose key is in flight in this process is rejected with http.StatusConflict.
+// Requests without the header go through unchanged.
+func Idempotency(store IdempotencyStore, scope func(r *http.Request) string) Middleware {
+	var (
+		mu       sync.Mutex
+		inflight = make(map[string]struct{})
+	)
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := r.Header.Get(IdempotencyKeyHeader)
+			if key == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			var caller string
+			if scope != nil {
+				caller = scope(r)
+			}
+			key = r.Method + " " + r.URL.EscapedPath() + " " + strconv.Quote(caller) + " " + key
+
+			hash, err := requestHash(r)
+			if err != nil {
+				WriteError(w, err)
+				return
+			}
+
+			mu.Lock()
+			if _, ok := inflight[key]; ok {
+				mu.Unlock()
+				DefaultErrorHandler(w, http.StatusConflict, ErrIdempotencyKeyInUse)
+				return
+			}
+			inflight[key] = struct{}{}
+			mu.Unlock()
+
+			defer func() {
+				mu.Lock()
+				delete(inflight, key)
+				mu.Unlock()
+			}()
+
+			resp, ok, err := store.Get(key)
+			if err != nil {
+				WriteError(w, err)
+				return
+			}
+			if ok {
+				if resp.RequestHash != hash {
+					DefaultErrorHandler(w, http.StatusUnprocessableEntity, ErrIdempotencyKeyReused)
+					return
+				}
+
+				replay(w, resp)
+				return
+			}
+
+			tw := &teeWriter{ResponseWriter: w, before: w.Header().Clone()}
+			rec := NewStatusRecorder(tw)
+			next.ServeHTTP(rec, r)
+
+			if rec.Status >= http.StatusInternalServerError {
+				return
+			}
+
+			_ = store.Set(key, IdempotentResponse{
+				Status:      rec.Status,
+				Header:      tw.handlerHeader(),
+				Body:        tw.body.Bytes(),
+				RequestHash: hash,
+			})
+		})
+	}
+}
+
+// requestHash returns the hex encoded SHA-256 of the body of r, and restores the body.
+// A body larger than DefaultMaxBodyBytes is rejected with http.StatusRequestEntityTooLarge.
+func requestHash(r *http.Request) (string, error) {
+	body, err := readBody(r, DefaultMaxBodyBytes)
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(body)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// replay writes a stored response.
+// The request ID of the current request is kept.
+func replay(w http.ResponseWriter, resp IdempotentResponse) {
+	h := w.Header()
+	for k, v := range resp.Header {
+		if k == RequestIDHeader {
+			continue
+		}
+		h[k] = append([]string(nil), v...)
+	}
+	h.Set("Idempotent-Replayed", "true")
+
+	w.WriteHeader(resp.Status)
+	_, _ = w.Write(resp.Body)
+}
+
+// teeWriter copies the body written to the response into body,
+// and records the headers set by the handler, the ones differing from before,
+// so the headers set by the outer middlewares for this request are not stored.
+type teeWriter struct {
+	http.ResponseWriter
+	body   bytes.Buffer
+	before http.Header
+	header http.Header
+}
+
+func (tw *teeWriter) WriteHeader(status int) {
+	if tw.header == nil {
+		tw.header = headerDiff(tw.before, tw.ResponseWriter.Header())
+	}
+
+	tw.ResponseWriter.WriteHeader(status)
+}
+
+func (tw *teeWriter) Write(b []byte) (int, error) {
+	if tw.header == nil {
+		tw.header = headerDiff(tw.before, tw.ResponseWriter.Header())
+	}
+
+	n, err := tw.ResponseWriter.Write(b)
+	tw.body.Write(b[:n])
+	return n, err
+}
+
+// handlerHeader returns the headers set by the handler.
+func (tw *teeWriter) handlerHeader() http.Header {
+	if tw.header == nil {
+		return headerDiff(tw.before, tw.ResponseWriter.Header())
+	}
+
+	return tw.header
+}
+
+// headerDiff returns a copy of the headers of after that are not in before with the same values.
+func headerDiff(before, after http.Header) http.Header {
+	diff := make(http.Header)
+	for k, v := range after {
+		if equalValues(before[k], v) {
+			continue
+		}
+		diff[k] = append([]string(nil), v...)
+	}
+
+	return diff
+}
+
+// equalValues reports whether a and b hold the same values in the same order.
+func equalValues(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Unwrap returns the underlying http.ResponseWriter.
+func (tw *teeWriter) Unwrap() http.ResponseWriter {
+	return tw.ResponseWriter
+}
@@ -0,0 +1,214 @@
+package httpwr
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// memoryIdempotencyStore is an in-memory IdempotencyStore.
+type memoryIdempotencyStore struct {
+	mu    sync.Mutex
+	items map[string]IdempotentResponse
+}
+
+func (s *memoryIdempotencyStore) Get(key string) (IdempotentResponse, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	resp, ok := s.items[key]
+	return resp, ok, nil
+}
+
+func (s *memoryIdempotencyStore) Set(key string, resp IdempotentResponse) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.items[key] = resp
+	return nil
+}
+
+func TestIdempotency(t *testing.T) {
+	store := &memoryIdempotencyStore{items: make(map[string]IdempotentResponse)}
+
+	var charges int
+	h := Idempotency(store, nil)(F(func(w http.ResponseWriter, r *http.Request) error {
+		charges++
+		return OKWithData(w, http.StatusCreated, CreatedMsg, M{"charge": charges})
+	}))
+
+	serve := func(key string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "/charges", nil)
+		if key != "" {
+			req.Header.Set(IdempotencyKeyHeader, key)
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		return w
+	}
+
+	want := `{"status":201,"msg":"Created","data":{"charge":1}}`
+
+	t.Run("first request", func(t *testing.T) {
+		w := serve("key-1")
+		if w.Code != http.StatusCreated {
+			t.Fatalf("expected http status created, got %d", w.Code)
+		}
+		if got := strings.TrimSpace(w.Body.String()); got != want {
+			t.Fatalf("expected body %s, got %s", want, got)
+		}
+	})
+
+	t.Run("replay", func(t *testing.T) {
+		w := serve("key-1")
+		if w.Code != http.StatusCreated {
+			t.Fatalf("expected http status created, got %d", w.Code)
+		}
+		if got := strings.TrimSpace(w.Body.String()); got != want {
+			t.Fatalf("expected body %s, got %s", want, got)
+		}
+		if got := w.Header().Get("Content-Type"); got != "application/json" {
+			t.Fatalf("expected Content-Type %q, got %q", "application/json", got)
+		}
+		if w.Header().Get("Idempotent-Replayed") != "true" {
+			t.Fatalf("expected Idempotent-Replayed header")
+		}
+		if charges != 1 {
+			t.Fatalf("expected the handler to run once, ran %d times", charges)
+		}
+	})
+
+	t.Run("missing key", func(t *testing.T) {
+		serve("")
+		serve("")
+		if charges != 3 {
+			t.Fatalf("expected the handler to run for every request without a key, ran %d times", charges)
+		}
+	})
+}
+
+func TestIdempotencyInFlight(t *testing.T) {
+	store := &memoryIdempotencyStore{items: make(map[string]IdempotentResponse)}
+
+	started, release := make(chan struct{}), make(chan struct{})
+	h := Idempotency(store, nil)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+		w.WriteHeader(http.StatusCreated)
+	}))
+
+	newRequest := func() *http.Request {
+		req := httptest.NewRequest("POST", "/charges", nil)
+		req.Header.Set(IdempotencyKeyHeader, "key-1")
+		return req
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		h.ServeHTTP(httptest.NewRecorder(), newRequest())
+	}()
+	<-started
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, newRequest())
+	close(release)
+	<-done
+
+	if w.Code != http.StatusConflict {
+		t.Fatalf("expected http status conflict, got %d", w.Code)
+	}
+}
+
+func TestIdempotencyScope(t *testing.T) {
+	store := &memoryIdempotencyStore{items: make(map[string]IdempotentResponse)}
+
+	var calls int
+	h := Idempotency(store, func(r *http.Request) string {
+		return r.Header.Get("X-User")
+	})(F(func(w http.ResponseWriter, r *http.Request) error {
+		calls++
+		return OKWithData(w, http.StatusCreated, CreatedMsg, M{"call": calls})
+	}))
+
+	serve := func(path, user, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", path, strings.NewReader(body))
+		req.Header.Set(IdempotencyKeyHeader, "key-1")
+		req.Header.Set("X-User", user)
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		return w
+	}
+
+	serve("/charges", "alice", `{"amount":10}`)
+
+	tests := []struct {
+		name       string
+		path       string
+		user       string
+		body       string
+		wantStatus int
+		wantCalls  int
+		replayed   bool
+	}{
+		{name: "same request", path: "/charges", user: "alice", body: `{"amount":10}`, wantStatus: http.StatusCreated, wantCalls: 1, replayed: true},
+		{name: "other path", path: "/refunds", user: "alice", body: `{"amount":10}`, wantStatus: http.StatusCreated, wantCalls: 2},
+		{name: "other caller", path: "/charges", user: "bob", body: `{"amount":10}`, wantStatus: http.StatusCreated, wantCalls: 3},
+		{name: "other body", path: "/charges", user: "alice", body: `{"amount":99}`, wantStatus: http.StatusUnprocessableEntity, wantCalls: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := serve(tt.path, tt.user, tt.body)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("expected http status %d, got %d", tt.wantStatus, w.Code)
+			}
+			if calls != tt.wantCalls {
+				t.Fatalf("expected the handler to run %d times, ran %d times", tt.wantCalls, calls)
+			}
+			if replayed := w.Header().Get("Idempotent-Replayed") == "true"; replayed != tt.replayed {
+				t.Fatalf("expected replayed to be %v, got %v", tt.replayed, replayed)
+			}
+		})
+	}
+}
+
+func TestIdempotencyReplayHeaders(t *testing.T) {
+	store := &memoryIdempotencyStore{items: make(map[string]IdempotentResponse)}
+
+	h := RequestID(Idempotency(store, nil)(F(func(w http.ResponseWriter, r *http.Request) error {
+		w.Header().Set("Location", "/charges/1")
+		return OKWithData(w, http.StatusCreated, CreatedMsg, M{"charge": 1})
+	})))
+
+	serve := func(requestID string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "/charges", nil)
+		req.Header.Set(IdempotencyKeyHeader, "key-1")
+		req.Header.Set(RequestIDHeader, requestID)
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		return w
+	}
+
+	serve("first")
+	w := serve("second")
+
+	if w.Header().Get("Idempotent-Replayed") != "true" {
+		t.Fatalf("expected the response to be replayed")
+	}
+	if got := w.Header().Get(RequestIDHeader); got != "second" {
+		t.Fatalf("expected %s %q, got %q", RequestIDHeader, "second", got)
+	}
+	if got := w.Header().Get("Location"); got != "/charges/1" {
+		t.Fatalf("expected the Location set by the handler to be replayed, got %q", got)
+	}
+
+	for _, resp := range store.items {
+		if _, ok := resp.Header[RequestIDHeader]; ok {
+			t.Fatalf("expected the headers of the outer middlewares not to be stored, got %v", resp.Header)
+		}
+	}
+}
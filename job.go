@@ -0,0 +1,41 @@
+package httpwr
+
+import (
+	"net/http"
+)
+
+// The states of an asynchronous job.
+const (
+	JobQueued    = "queued"
+	JobRunning   = "running"
+	JobCompleted = "completed"
+	JobFailed    = "failed"
+	JobCanceled  = "canceled"
+)
+
+// JobStatus sends the state of an asynchronous job for polling endpoints.
+// Terminal states (JobCompleted, JobFailed and JobCanceled) are sent with http.StatusOK,
+// any other state is still in progress and is sent with http.StatusAccepted.
+func JobStatus(w http.ResponseWriter, state string, progress int, result M) error {
+	status := http.StatusAccepted
+	switch state {
+	case JobCompleted, JobFailed, JobCanceled:
+		status = http.StatusOK
+	}
+
+	type r struct {
+		Status   int    `json:"status,omitempty"`
+		State    string `json:"state"`
+		Progress int    `json:"progress"`
+		Result   M      `json:"result,omitempty"`
+	}
+
+	_ = writeJSON(w, status, r{
+		Status:   bodyStatus(status),
+		State:    state,
+		Progress: progress,
+		Result:   result,
+	})
+
+	return nil
+}
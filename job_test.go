@@ -0,0 +1,60 @@
+package httpwr
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestJobStatus(t *testing.T) {
+	tests := []struct {
+		name       string
+		state      string
+		progress   int
+		result     M
+		wantStatus int
+		wantBody   []string
+	}{
+		{
+			name:       "in progress",
+			state:      JobRunning,
+			progress:   40,
+			wantStatus: http.StatusAccepted,
+			wantBody:   []string{`"state":"running"`, `"progress":40`},
+		},
+		{
+			name:       "completed",
+			state:      JobCompleted,
+			progress:   100,
+			result:     M{"url": "/reports/1"},
+			wantStatus: http.StatusOK,
+			wantBody:   []string{`"state":"completed"`, `"result":{"url":"/reports/1"}`},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/jobs/1", nil)
+			w := httptest.NewRecorder()
+			F(func(w http.ResponseWriter, r *http.Request) error {
+				return JobStatus(w, tt.state, tt.progress, tt.result)
+			}).ServeHTTP(w, req)
+			resp := w.Result()
+
+			if resp.StatusCode != tt.wantStatus {
+				t.Fatalf("expected http status %d, got %d", tt.wantStatus, resp.StatusCode)
+			}
+
+			bts, err := io.ReadAll(resp.Body)
+			if err != nil {
+				t.Fatalf("got error: %v", err)
+			}
+			for _, want := range tt.wantBody {
+				if !strings.Contains(string(bts), want) {
+					t.Fatalf("%q does not contain %q", string(bts), want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,32 @@
+package httpwr
+
+import (
+	"context"
+	"net/http"
+)
+
+// JSONHandler returns a handler decoding the JSON request body into In, like Decode,
+// calling fn with the request context, and sending the returned Out as JSON
+// with the returned status, http.StatusOK if it is zero.
+// A decoding error is sent with http.StatusBadRequest, or http.StatusRequestEntityTooLarge
+// for a body that is too large. An error returned by fn is rendered like the handlers
+// returned by New, so an Error keeps its status.
+func JSONHandler[In, Out any](fn func(context.Context, In) (Out, int, error)) http.Handler {
+	return HandlerFn(func(w http.ResponseWriter, r *http.Request) error {
+		var in In
+		if err := Decode(r, &in); err != nil {
+			return err
+		}
+
+		out, status, err := fn(r.Context(), in)
+		if err != nil {
+			return err
+		}
+
+		if status == 0 {
+			status = http.StatusOK
+		}
+
+		return writeJSON(w, status, out)
+	})
+}
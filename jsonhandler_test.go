@@ -0,0 +1,70 @@
+package httpwr
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type createUserRequest struct {
+	Name string `json:"name"`
+}
+
+type createUserResponse struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+func TestJSONHandler(t *testing.T) {
+	errExists := errors.New("user already exists")
+
+	h := JSONHandler(func(ctx context.Context, in createUserRequest) (createUserResponse, int, error) {
+		if in.Name == "taken" {
+			return createUserResponse{}, 0, Wrap(http.StatusConflict, errExists)
+		}
+		return createUserResponse{ID: 1, Name: in.Name}, http.StatusCreated, nil
+	})
+
+	tests := []struct {
+		name       string
+		body       string
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name:       "round trip",
+			body:       `{"name":"samuel"}`,
+			wantStatus: http.StatusCreated,
+			wantBody:   `{"id":1,"name":"samuel"}`,
+		},
+		{
+			name:       "decode failure",
+			body:       `{"name":1}`,
+			wantStatus: http.StatusBadRequest,
+			wantBody:   `{"status":400,"error":"invalid value at /name: expected string, got number"}`,
+		},
+		{
+			name:       "business error",
+			body:       `{"name":"taken"}`,
+			wantStatus: http.StatusConflict,
+			wantBody:   `{"status":409,"error":"user already exists"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, httptest.NewRequest("POST", "/users", strings.NewReader(tt.body)))
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("expected http status %d, got %d", tt.wantStatus, w.Code)
+			}
+			if got := strings.TrimSpace(w.Body.String()); got != tt.wantBody {
+				t.Fatalf("expected body %s, got %s", tt.wantBody, got)
+			}
+		})
+	}
+}
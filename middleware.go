@@ -0,0 +1,251 @@
+package httpwr
+
+import (
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// Middleware wraps a http.Handler with additional behavior.
+type Middleware func(http.Handler) http.Handler
+
+// Chain composes the middlewares into a single Middleware.
+// The first middleware is the outermost one, so it runs first.
+func Chain(mws ...Middleware) Middleware {
+	return func(next http.Handler) http.Handler {
+		for i := len(mws) - 1; i >= 0; i-- {
+			next = mws[i](next)
+		}
+		return next
+	}
+}
+
+// Recover recovers from panics in the handler and renders them with DefaultErrorHandler.
+// If the panic value is an Error, like panic(httpwr.Errorf(http.StatusForbidden, "...")),
+// it is rendered with its status. Any other value is rendered as ErrInternalServerError
+// with http.StatusInternalServerError, without leaking the panic value to the client.
+// http.ErrAbortHandler is panicked again, so the server can abort the response.
+func Recover(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer func() {
+			v := recover()
+			if v == nil {
+				return
+			}
+
+			if v == http.ErrAbortHandler {
+				panic(v)
+			}
+
+			if err, ok := v.(error); ok && errors.Is(err, Error{}) {
+				handleError(w, err, DefaultErrorHandler)
+				return
+			}
+
+			DefaultErrorHandler(w, http.StatusInternalServerError, ErrInternalServerError)
+		}()
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// SizeProfile reports the size of the request body and the response body
+// of every request to observe. It helps finding oversized payloads.
+func SizeProfile(observe func(reqBytes, respBytes int64)) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body := &countingReader{ReadCloser: r.Body}
+			if r.Body != nil {
+				r.Body = body
+			}
+
+			rec := NewStatusRecorder(w)
+			next.ServeHTTP(rec, r)
+
+			observe(body.n, rec.Bytes)
+		})
+	}
+}
+
+// ErrUploadBudgetExceeded is sent when UploadGate rejects an upload.
+var ErrUploadBudgetExceeded = errors.New("too many concurrent uploads, try again later")
+
+// ErrUploadTooLarge is sent when UploadGate rejects an upload larger than the whole budget.
+var ErrUploadTooLarge = errors.New("upload is too large")
+
+// UploadGate bounds the memory used by concurrent uploads.
+// It tracks the sum of the declared Content-Length of the in-flight requests,
+// and rejects a request with http.StatusServiceUnavailable when admitting it
+// would exceed maxConcurrentBytes.
+// A request larger than maxConcurrentBytes on its own can never be admitted,
+// so it is rejected with http.StatusRequestEntityTooLarge instead.
+// Requests without a declared Content-Length are not counted.
+func UploadGate(maxConcurrentBytes int64) func(http.Handler) http.Handler {
+	var (
+		mu       sync.Mutex
+		inflight int64
+	)
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			size := r.ContentLength
+			if size <= 0 {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if size > maxConcurrentBytes {
+				DefaultErrorHandler(w, http.StatusRequestEntityTooLarge, ErrUploadTooLarge)
+				return
+			}
+
+			mu.Lock()
+			if inflight+size > maxConcurrentBytes {
+				mu.Unlock()
+				DefaultErrorHandler(w, http.StatusServiceUnavailable, ErrUploadBudgetExceeded)
+				return
+			}
+			inflight += size
+			mu.Unlock()
+
+			defer func() {
+				mu.Lock()
+				inflight -= size
+				mu.Unlock()
+			}()
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// countingReader counts the bytes read from the underlying reader.
+type countingReader struct {
+	io.ReadCloser
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.ReadCloser.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// SuppressBodyOnHead discards the response body of HEAD requests,
+// while keeping the headers like Content-Type and Content-Length set by the handler.
+func SuppressBodyOnHead(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodHead {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		next.ServeHTTP(headWriter{w}, r)
+	})
+}
+
+// headWriter discards everything written to the body.
+type headWriter struct {
+	http.ResponseWriter
+}
+
+func (hw headWriter) Write(b []byte) (int, error) {
+	return len(b), nil
+}
+
+// Unwrap returns the underlying http.ResponseWriter.
+func (hw headWriter) Unwrap() http.ResponseWriter {
+	return hw.ResponseWriter
+}
+
+// ErrURITooLong is sent when MaxURLLength rejects a request.
+var ErrURITooLong = errors.New("uri too long")
+
+// MaxURLLength rejects requests whose URL is longer than n bytes
+// with http.StatusRequestURITooLong, defending against abusive query strings.
+func MaxURLLength(n int) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if len(r.URL.String()) > n {
+				DefaultErrorHandler(w, http.StatusRequestURITooLong, ErrURITooLong)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// ErrorStats counts the error responses, with a status of 400 or above, by status.
+// The returned middleware does the counting, and snapshot returns a copy of the counts,
+// for lightweight in-process error dashboards.
+func ErrorStats() (mw func(http.Handler) http.Handler, snapshot func() map[int]int) {
+	var (
+		mu     sync.Mutex
+		counts = make(map[int]int)
+	)
+
+	mw = func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			rec := NewStatusRecorder(w)
+			next.ServeHTTP(rec, r)
+
+			if rec.Status < http.StatusBadRequest {
+				return
+			}
+
+			mu.Lock()
+			counts[rec.Status]++
+			mu.Unlock()
+		})
+	}
+
+	snapshot = func() map[int]int {
+		mu.Lock()
+		defer mu.Unlock()
+
+		out := make(map[int]int, len(counts))
+		for status, n := range counts {
+			out[status] = n
+		}
+
+		return out
+	}
+
+	return mw, snapshot
+}
+
+// RequireContentType rejects the POST, PUT and PATCH requests with a body whose Content-Type
+// is not one of the given media types, with UnsupportedMediaType.
+// The parameters, like `; charset=utf-8`, are ignored, and the media types are compared
+// case insensitively. Requests without a body or with other methods are passed through.
+func RequireContentType(types ...string) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.Method {
+			case http.MethodPost, http.MethodPut, http.MethodPatch:
+			default:
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if r.ContentLength == 0 && (r.Body == nil || r.Body == http.NoBody) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+			for _, t := range types {
+				if strings.EqualFold(mediaType, t) {
+					next.ServeHTTP(w, r)
+					return
+				}
+			}
+
+			_ = UnsupportedMediaType(w, mediaType, types...)
+		})
+	}
+}
@@ -0,0 +1,284 @@
+package httpwr
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestSizeProfile(t *testing.T) {
+	var gotReq, gotResp int64
+	mw := SizeProfile(func(reqBytes, respBytes int64) {
+		gotReq, gotResp = reqBytes, respBytes
+	})
+
+	body := "hello world"
+	req := httptest.NewRequest("POST", "/size", strings.NewReader(body))
+	w := httptest.NewRecorder()
+	mw(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.ReadAll(r.Body)
+		_, _ = w.Write([]byte("12345"))
+	})).ServeHTTP(w, req)
+
+	if gotReq != int64(len(body)) {
+		t.Fatalf("expected request size %d, got %d", len(body), gotReq)
+	}
+	if gotResp != 5 {
+		t.Fatalf("expected response size %d, got %d", 5, gotResp)
+	}
+}
+
+func TestChain(t *testing.T) {
+	var order []string
+	mw := func(name string) Middleware {
+		return func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				order = append(order, name)
+				next.ServeHTTP(w, r)
+			})
+		}
+	}
+
+	h := Chain(mw("first"), mw("second"))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		order = append(order, "handler")
+	}))
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+
+	if got := strings.Join(order, ","); got != "first,second,handler" {
+		t.Fatalf("unexpected order: %s", got)
+	}
+}
+
+func TestSuppressBodyOnHead(t *testing.T) {
+	h := SuppressBodyOnHead(F(func(w http.ResponseWriter, r *http.Request) error {
+		return OK(w, http.StatusOK, "all good")
+	}))
+
+	t.Run("head", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest("HEAD", "/", nil))
+		resp := w.Result()
+
+		if resp.Header.Get("Content-Type") != "application/json" {
+			t.Fatalf("expected application/json, got %s", resp.Header.Get("Content-Type"))
+		}
+		if resp.Header.Get("Content-Length") == "" || resp.Header.Get("Content-Length") == "0" {
+			t.Fatalf("expected Content-Length of the body, got %q", resp.Header.Get("Content-Length"))
+		}
+
+		bts, err := io.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatalf("got error: %v", err)
+		}
+		if len(bts) != 0 {
+			t.Fatalf("expected empty body, got %q", string(bts))
+		}
+	})
+
+	t.Run("get", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+		resp := w.Result()
+
+		bts, err := io.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatalf("got error: %v", err)
+		}
+		if !strings.Contains(string(bts), "all good") {
+			t.Fatalf("%q does not contain %q", string(bts), "all good")
+		}
+		if resp.Header.Get("Content-Length") != strconv.Itoa(len(bts)) {
+			t.Fatalf("expected Content-Length %d, got %q", len(bts), resp.Header.Get("Content-Length"))
+		}
+	})
+}
+
+func TestUploadGate(t *testing.T) {
+	release := make(chan struct{})
+	started := make(chan struct{}, 2)
+	h := UploadGate(100)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		started <- struct{}{}
+		<-release
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	upload := func(size int) *http.Request {
+		return httptest.NewRequest("POST", "/upload", strings.NewReader(strings.Repeat("a", size)))
+	}
+
+	done := make(chan int, 2)
+	for _, size := range []int{60, 40} {
+		req := upload(size)
+		go func() {
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, req)
+			done <- w.Code
+		}()
+	}
+	<-started
+	<-started
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, upload(1))
+	if w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected http status %d, got %d", http.StatusServiceUnavailable, w.Code)
+	}
+
+	close(release)
+	for i := 0; i < 2; i++ {
+		if code := <-done; code != http.StatusOK {
+			t.Fatalf("expected http status ok, got %d", code)
+		}
+	}
+
+	// the budget is released once the uploads finish.
+	release = make(chan struct{})
+	close(release)
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, upload(100))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected http status ok, got %d", w.Code)
+	}
+	// an upload larger than the whole budget can never be admitted.
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, upload(101))
+	if w.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("expected http status %d, got %d", http.StatusRequestEntityTooLarge, w.Code)
+	}
+}
+
+func TestRecover(t *testing.T) {
+	tests := []struct {
+		name       string
+		value      any
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name:       "error",
+			value:      Errorf(http.StatusForbidden, "no access"),
+			wantStatus: http.StatusForbidden,
+			wantBody:   "no access",
+		},
+		{
+			name:       "string",
+			value:      "something broke",
+			wantStatus: http.StatusInternalServerError,
+			wantBody:   ErrInternalServerError.Error(),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := Recover(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				panic(tt.value)
+			}))
+
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, httptest.NewRequest("GET", "/panic", nil))
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("expected http status %d, got %d", tt.wantStatus, w.Code)
+			}
+			if !strings.Contains(w.Body.String(), tt.wantBody) {
+				t.Fatalf("%q does not contain %q", w.Body.String(), tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestMaxURLLength(t *testing.T) {
+	h := MaxURLLength(32)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	tests := []struct {
+		name       string
+		url        string
+		wantStatus int
+	}{
+		{name: "under limit", url: "/users?page=2", wantStatus: http.StatusOK},
+		{name: "over limit", url: "/users?q=" + strings.Repeat("a", 64), wantStatus: http.StatusRequestURITooLong},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, httptest.NewRequest("GET", tt.url, nil))
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("expected http status %d, got %d", tt.wantStatus, w.Code)
+			}
+		})
+	}
+}
+
+func TestErrorStats(t *testing.T) {
+	mw, snapshot := ErrorStats()
+
+	h := mw(F(func(w http.ResponseWriter, r *http.Request) error {
+		switch r.URL.Path {
+		case "/bad":
+			return Wrap(http.StatusBadRequest, ErrBadRequest)
+		case "/fail":
+			return errors.New("boom")
+		default:
+			return OK(w, http.StatusOK, OKMsg)
+		}
+	}))
+
+	for _, path := range []string{"/bad", "/fail", "/ok", "/bad"} {
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", path, nil))
+	}
+
+	want := map[int]int{http.StatusBadRequest: 2, http.StatusInternalServerError: 1}
+	if got := snapshot(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected counts %v, got %v", want, got)
+	}
+}
+
+func TestRequireContentType(t *testing.T) {
+	h := RequireContentType("application/json")(F(func(w http.ResponseWriter, r *http.Request) error {
+		return OK(w, http.StatusOK, OKMsg)
+	}))
+
+	tests := []struct {
+		name        string
+		method      string
+		contentType string
+		body        string
+		want        int
+	}{
+		{name: "matching type", method: http.MethodPost, contentType: "application/json; charset=utf-8", body: `{}`, want: http.StatusOK},
+		{name: "mismatched type", method: http.MethodPut, contentType: "text/plain", body: `{}`, want: http.StatusUnsupportedMediaType},
+		{name: "missing type", method: http.MethodPatch, body: `{}`, want: http.StatusUnsupportedMediaType},
+		{name: "post without body", method: http.MethodPost, want: http.StatusOK},
+		{name: "get without body", method: http.MethodGet, want: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var body io.Reader
+			if tt.body != "" {
+				body = strings.NewReader(tt.body)
+			}
+			req := httptest.NewRequest(tt.method, "/users", body)
+			if tt.contentType != "" {
+				req.Header.Set("Content-Type", tt.contentType)
+			}
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, req)
+
+			if w.Code != tt.want {
+				t.Fatalf("expected http status %d, got %d", tt.want, w.Code)
+			}
+			if tt.want == http.StatusUnsupportedMediaType && w.Header().Get("Accept-Post") != "application/json" {
+				t.Fatalf("expected Accept-Post %q, got %q", "application/json", w.Header().Get("Accept-Post"))
+			}
+		})
+	}
+}
@@ -0,0 +1,54 @@
+package httpwr
+
+import (
+	"errors"
+	"net/http"
+)
+
+// ErrNoMsgpackEncoder is returned by Msgpack when no encoder was set with SetMsgpackEncoder.
+var ErrNoMsgpackEncoder = errors.New("httpwr: no msgpack encoder, call SetMsgpackEncoder")
+
+// MsgpackEncoder encodes values as msgpack.
+// It is an interface, so this package does not depend on a msgpack library.
+type MsgpackEncoder interface {
+	Marshal(v any) ([]byte, error)
+}
+
+// MarshalFunc adapts a function like msgpack.Marshal from github.com/vmihailenco/msgpack/v5
+// to a MsgpackEncoder.
+type MarshalFunc func(v any) ([]byte, error)
+
+// Marshal calls f(v).
+func (f MarshalFunc) Marshal(v any) ([]byte, error) {
+	return f(v)
+}
+
+// msgpackEncoder is the encoder used by Msgpack, nil disables msgpack.
+var msgpackEncoder MsgpackEncoder
+
+// SetMsgpackEncoder sets the encoder used by Msgpack and Respond, like
+//
+//	httpwr.SetMsgpackEncoder(httpwr.MarshalFunc(msgpack.Marshal))
+//
+// Pass nil to disable msgpack, which is the default.
+// Call it before serving requests, it is not safe for concurrent use.
+func SetMsgpackEncoder(enc MsgpackEncoder) {
+	msgpackEncoder = enc
+}
+
+// Msgpack encodes data as msgpack and sends it with the given status.
+// It returns ErrNoMsgpackEncoder if no encoder was set with SetMsgpackEncoder.
+// The body is encoded before anything is written, so an encoding error
+// never leaves a partially written response.
+func Msgpack(w http.ResponseWriter, status int, data any) error {
+	if msgpackEncoder == nil {
+		return ErrNoMsgpackEncoder
+	}
+
+	body, err := msgpackEncoder.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	return writeBody(w, status, "application/msgpack", body)
+}
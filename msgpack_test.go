@@ -0,0 +1,97 @@
+package httpwr
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+// testMsgpackBody is the body returned by the stub encoder.
+var testMsgpackBody = []byte{0x81, 0xa4, 'n', 'a', 'm', 'e', 0xa6, 's', 'a', 'm', 'u', 'e', 'l'}
+
+// stubMsgpack returns an encoder returning testMsgpackBody and recording the encoded value.
+func stubMsgpack(got *any) MsgpackEncoder {
+	return MarshalFunc(func(v any) ([]byte, error) {
+		*got = v
+		return testMsgpackBody, nil
+	})
+}
+
+func TestMsgpack(t *testing.T) {
+	var encoded any
+	SetMsgpackEncoder(stubMsgpack(&encoded))
+	t.Cleanup(func() { SetMsgpackEncoder(nil) })
+
+	data := M{"name": "samuel"}
+
+	w := httptest.NewRecorder()
+	if err := Msgpack(w, http.StatusCreated, data); err != nil {
+		t.Fatalf("got error: %v", err)
+	}
+
+	if !reflect.DeepEqual(encoded, data) {
+		t.Fatalf("expected the encoder to be called with %v, got %v", data, encoded)
+	}
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected http status created, got %d", w.Code)
+	}
+	if got := w.Header().Get("Content-Type"); got != "application/msgpack" {
+		t.Fatalf("expected Content-Type %q, got %q", "application/msgpack", got)
+	}
+	if !bytes.Equal(w.Body.Bytes(), testMsgpackBody) {
+		t.Fatalf("expected body %x, got %x", testMsgpackBody, w.Body.Bytes())
+	}
+}
+
+func TestMsgpackWithoutEncoder(t *testing.T) {
+	w := httptest.NewRecorder()
+	if err := Msgpack(w, http.StatusOK, M{}); !errors.Is(err, ErrNoMsgpackEncoder) {
+		t.Fatalf("expected ErrNoMsgpackEncoder, got %v", err)
+	}
+	if w.Body.Len() != 0 {
+		t.Fatalf("expected nothing to be written, got %q", w.Body.String())
+	}
+}
+
+func TestRespondMsgpack(t *testing.T) {
+	var encoded any
+	SetMsgpackEncoder(stubMsgpack(&encoded))
+	t.Cleanup(func() { SetMsgpackEncoder(nil) })
+
+	tests := []struct {
+		accept      string
+		contentType string
+	}{
+		{accept: "application/msgpack", contentType: "application/msgpack"},
+		{accept: "application/json;q=0.5, application/msgpack", contentType: "application/msgpack"},
+		{accept: "application/json, application/msgpack;q=0.9", contentType: "application/json"},
+		{accept: "*/*", contentType: "application/json"},
+		{accept: "", contentType: "application/json"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.accept, func(t *testing.T) {
+			encoded = nil
+			req := httptest.NewRequest("GET", "/", nil)
+			req.Header.Set("Accept", tt.accept)
+			w := httptest.NewRecorder()
+
+			if err := Respond(w, req, http.StatusOK, M{"name": "samuel"}); err != nil {
+				t.Fatalf("got error: %v", err)
+			}
+
+			if got := w.Header().Get("Content-Type"); got != tt.contentType {
+				t.Fatalf("expected Content-Type %q, got %q", tt.contentType, got)
+			}
+			if called := encoded != nil; called != (tt.contentType == "application/msgpack") {
+				t.Fatalf("expected the encoder to be called only for msgpack, called: %v", called)
+			}
+			if got := w.Header().Get("Vary"); got != "Accept" {
+				t.Fatalf("expected Vary %q, got %q", "Accept", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,43 @@
+package httpwr
+
+import "net/http"
+
+// Mux is a http.ServeMux registering error returning handlers.
+// Every handler is wrapped with the error handler and the middlewares of the Mux,
+// so they do not have to be wired per route.
+// Plain http.Handlers can still be registered on the embedded ServeMux.
+type Mux struct {
+	*http.ServeMux
+
+	eh  ErrorHandler
+	mws Middleware
+}
+
+// NewServeMux returns a Mux rendering the errors with eh, DefaultErrorHandler if nil,
+// and wrapping the handlers with mws, the first one being the outermost.
+func NewServeMux(eh ErrorHandler, mws ...Middleware) *Mux {
+	if eh == nil {
+		eh = DefaultErrorHandler
+	}
+
+	return &Mux{
+		ServeMux: http.NewServeMux(),
+		eh:       eh,
+		mws:      Chain(mws...),
+	}
+}
+
+// Handle registers h for the given pattern, like http.ServeMux.Handle.
+func (m *Mux) Handle(pattern string, h HandlerFunc) {
+	m.ServeMux.Handle(pattern, m.mws(NewWithHandler(h, m.eh)))
+}
+
+// HandleFunc registers fn for the given pattern, like http.ServeMux.HandleFunc.
+func (m *Mux) HandleFunc(pattern string, fn func(http.ResponseWriter, *http.Request) error) {
+	m.Handle(pattern, fn)
+}
+
+// ServeHTTP dispatches the request to the handler registered for its path.
+func (m *Mux) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	m.ServeMux.ServeHTTP(w, r)
+}
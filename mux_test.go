@@ -0,0 +1,51 @@
+package httpwr
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestMux(t *testing.T) {
+	tag := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("X-Mux", "yes")
+			next.ServeHTTP(w, r)
+		})
+	}
+
+	mux := NewServeMux(nil, tag)
+	mux.HandleFunc("/users", func(w http.ResponseWriter, r *http.Request) error {
+		return OK(w, http.StatusOK, "users")
+	})
+	mux.Handle("/fail", func(w http.ResponseWriter, r *http.Request) error {
+		return Errorf(http.StatusConflict, "conflict")
+	})
+
+	tests := []struct {
+		path       string
+		wantStatus int
+		wantBody   string
+	}{
+		{path: "/users", wantStatus: http.StatusOK, wantBody: `{"status":200,"msg":"users"}`},
+		{path: "/fail", wantStatus: http.StatusConflict, wantBody: `{"status":409,"error":"conflict"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			mux.ServeHTTP(w, httptest.NewRequest("GET", tt.path, nil))
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("expected http status %d, got %d", tt.wantStatus, w.Code)
+			}
+			if got := strings.TrimSpace(w.Body.String()); got != tt.wantBody {
+				t.Fatalf("expected body %s, got %s", tt.wantBody, got)
+			}
+			if w.Header().Get("X-Mux") != "yes" {
+				t.Fatalf("expected the middleware to run")
+			}
+		})
+	}
+}
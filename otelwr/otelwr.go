@@ -0,0 +1,58 @@
+// Package otelwr provides an OpenTelemetry tracing middleware for httpwr.
+package otelwr
+
+import (
+	"net/http"
+
+	"github.com/samuelsih/httpwr"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const tracerName = "github.com/samuelsih/httpwr/otelwr"
+
+// Trace starts a span for every request using the given TracerProvider.
+// The incoming trace context is extracted from the request headers
+// using the global propagator.
+//
+// The span is named after the route pattern returned by route.
+// If route is nil, the raw URL path is used.
+// The span status is set to error when the final status is 5xx,
+// which includes a returned httpwr.Error with a 5xx status.
+func Trace(tp trace.TracerProvider, route func(*http.Request) string) httpwr.Middleware {
+	if route == nil {
+		route = func(r *http.Request) string {
+			return r.URL.Path
+		}
+	}
+
+	tracer := tp.Tracer(tracerName)
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			propagator := otel.GetTextMapPropagator()
+			ctx := propagator.Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+
+			pattern := route(r)
+			ctx, span := tracer.Start(ctx, r.Method+" "+pattern,
+				trace.WithSpanKind(trace.SpanKindServer),
+				trace.WithAttributes(
+					semconv.HTTPRequestMethodKey.String(r.Method),
+					semconv.HTTPRoute(pattern),
+				),
+			)
+			defer span.End()
+
+			rec := httpwr.NewStatusRecorder(w)
+			next.ServeHTTP(rec, r.WithContext(ctx))
+
+			span.SetAttributes(semconv.HTTPResponseStatusCode(rec.Status))
+			if rec.Status >= http.StatusInternalServerError {
+				span.SetStatus(codes.Error, http.StatusText(rec.Status))
+			}
+		})
+	}
+}
@@ -0,0 +1,98 @@
+package otelwr
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/samuelsih/httpwr"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestTrace(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+
+	mw := Trace(tp, func(r *http.Request) string {
+		return "/users/{id}"
+	})
+
+	req := httptest.NewRequest("GET", "/users/1", nil)
+	w := httptest.NewRecorder()
+	mw(httpwr.F(func(w http.ResponseWriter, r *http.Request) error {
+		return httpwr.Errorf(http.StatusBadGateway, "upstream is down")
+	})).ServeHTTP(w, req)
+
+	spans := exporter.GetSpans()
+	if len(spans) != 1 {
+		t.Fatalf("expected 1 span, got %d", len(spans))
+	}
+
+	span := spans[0]
+	if span.Name != "GET /users/{id}" {
+		t.Fatalf("expected span name %q, got %q", "GET /users/{id}", span.Name)
+	}
+	if span.Status.Code != codes.Error {
+		t.Fatalf("expected span status error, got %v", span.Status.Code)
+	}
+
+	attrs := map[attribute.Key]attribute.Value{}
+	for _, kv := range span.Attributes {
+		attrs[kv.Key] = kv.Value
+	}
+	if got := attrs["http.request.method"].AsString(); got != "GET" {
+		t.Fatalf("expected method GET, got %q", got)
+	}
+	if got := attrs["http.response.status_code"].AsInt64(); got != http.StatusBadGateway {
+		t.Fatalf("expected status code %d, got %d", http.StatusBadGateway, got)
+	}
+}
+
+func TestTraceOK(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+
+	req := httptest.NewRequest("GET", "/ok", nil)
+	w := httptest.NewRecorder()
+	Trace(tp, nil)(httpwr.F(func(w http.ResponseWriter, r *http.Request) error {
+		return httpwr.OK(w, http.StatusOK, httpwr.OKMsg)
+	})).ServeHTTP(w, req)
+
+	spans := exporter.GetSpans()
+	if len(spans) != 1 {
+		t.Fatalf("expected 1 span, got %d", len(spans))
+	}
+	if spans[0].Status.Code == codes.Error {
+		t.Fatalf("expected span status not to be error")
+	}
+}
+
+func TestTracePropagation(t *testing.T) {
+	prev := otel.GetTextMapPropagator()
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() { otel.SetTextMapPropagator(prev) })
+
+	exporter := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+
+	traceID := "4bf92f3577b34da6a3ce929d0e0e4736"
+	req := httptest.NewRequest("GET", "/ok", nil)
+	req.Header.Set("traceparent", "00-"+traceID+"-00f067aa0ba902b7-01")
+	w := httptest.NewRecorder()
+	Trace(tp, nil)(httpwr.F(func(w http.ResponseWriter, r *http.Request) error {
+		return nil
+	})).ServeHTTP(w, req)
+
+	spans := exporter.GetSpans()
+	if len(spans) != 1 {
+		t.Fatalf("expected 1 span, got %d", len(spans))
+	}
+	if got := spans[0].SpanContext.TraceID().String(); got != traceID {
+		t.Fatalf("expected trace id %q, got %q", traceID, got)
+	}
+}
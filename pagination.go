@@ -0,0 +1,52 @@
+package httpwr
+
+import (
+	"net/http"
+)
+
+// Paginated is a page of items returned by a list endpoint.
+type Paginated[T any] struct {
+	Items   []T
+	Page    int
+	PerPage int
+	Total   int
+}
+
+// TotalPages returns the number of pages needed to list all the items.
+// It returns 0 if PerPage is not positive.
+func (p Paginated[T]) TotalPages() int {
+	if p.PerPage <= 0 {
+		return 0
+	}
+
+	return (p.Total + p.PerPage - 1) / p.PerPage
+}
+
+// WritePaginated converts the page and its metadata to JSON and sends it to user.
+// Nil items are written as an empty array instead of null.
+func WritePaginated[T any](w http.ResponseWriter, status int, p Paginated[T]) error {
+	items := p.Items
+	if items == nil {
+		items = []T{}
+	}
+
+	type r struct {
+		Status     int `json:"status,omitempty"`
+		Items      []T `json:"items"`
+		Page       int `json:"page"`
+		PerPage    int `json:"per_page"`
+		Total      int `json:"total"`
+		TotalPages int `json:"total_pages"`
+	}
+
+	_ = writeJSON(w, status, r{
+		Status:     bodyStatus(status),
+		Items:      items,
+		Page:       p.Page,
+		PerPage:    p.PerPage,
+		Total:      p.Total,
+		TotalPages: p.TotalPages(),
+	})
+
+	return nil
+}
@@ -0,0 +1,54 @@
+package httpwr
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestTotalPages(t *testing.T) {
+	tests := []struct {
+		name    string
+		total   int
+		perPage int
+		want    int
+	}{
+		{name: "exact", total: 20, perPage: 10, want: 2},
+		{name: "remainder", total: 21, perPage: 10, want: 3},
+		{name: "empty", total: 0, perPage: 10, want: 0},
+		{name: "zero per page", total: 21, perPage: 0, want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := Paginated[int]{Total: tt.total, PerPage: tt.perPage}
+			if got := p.TotalPages(); got != tt.want {
+				t.Fatalf("expected %d pages, got %d", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestWritePaginatedEmpty(t *testing.T) {
+	req := httptest.NewRequest("GET", "/list", nil)
+	w := httptest.NewRecorder()
+	F(func(w http.ResponseWriter, r *http.Request) error {
+		return WritePaginated(w, http.StatusOK, Paginated[string]{Page: 1, PerPage: 10})
+	}).ServeHTTP(w, req)
+	resp := w.Result()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected http status ok, got %d", resp.StatusCode)
+	}
+
+	bts, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("got error: %v", err)
+	}
+	for _, want := range []string{`"items":[]`, `"total_pages":0`} {
+		if !strings.Contains(string(bts), want) {
+			t.Fatalf("%q does not contain %q", string(bts), want)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package httpwr
+
+import (
+	"net/http"
+)
+
+// PathParamExtractor extracts the path parameters matched by a router.
+type PathParamExtractor interface {
+	PathParam(r *http.Request, key string) string
+}
+
+// PathParamFunc adapts a function returning a single parameter into a PathParamExtractor.
+// It matches chi.URLParam:
+//
+//	httpwr.SetPathParamExtractor(httpwr.PathParamFunc(chi.URLParam))
+type PathParamFunc func(r *http.Request, key string) string
+
+// PathParam calls f(r, key).
+func (f PathParamFunc) PathParam(r *http.Request, key string) string {
+	return f(r, key)
+}
+
+// VarsFunc adapts a function returning all the parameters into a PathParamExtractor.
+// It matches mux.Vars from gorilla/mux:
+//
+//	httpwr.SetPathParamExtractor(httpwr.VarsFunc(mux.Vars))
+type VarsFunc func(r *http.Request) map[string]string
+
+// PathParam returns the key from f(r).
+func (f VarsFunc) PathParam(r *http.Request, key string) string {
+	return f(r)[key]
+}
+
+// pathParamExtractor is the extractor used by PathParam.
+var pathParamExtractor PathParamExtractor = PathParamFunc(pathValue)
+
+// SetPathParamExtractor sets the extractor used by PathParam.
+// By default, the path values of http.ServeMux are used when available (Go 1.22+).
+// Call it before serving requests, it is not safe for concurrent use.
+func SetPathParamExtractor(e PathParamExtractor) {
+	if e == nil {
+		e = PathParamFunc(pathValue)
+	}
+
+	pathParamExtractor = e
+}
+
+// PathParam returns the path parameter named key,
+// or an empty string if there is none.
+func PathParam(r *http.Request, key string) string {
+	return pathParamExtractor.PathParam(r, key)
+}
+
+// pathValue returns the path value matched by http.ServeMux,
+// without requiring a Go version where Request.PathValue exists.
+func pathValue(r *http.Request, key string) string {
+	if pv, ok := any(r).(interface{ PathValue(string) string }); ok {
+		return pv.PathValue(key)
+	}
+
+	return ""
+}
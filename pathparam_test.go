@@ -0,0 +1,39 @@
+package httpwr
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestPathParam(t *testing.T) {
+	t.Cleanup(func() { SetPathParamExtractor(nil) })
+
+	req := httptest.NewRequest("GET", "/users/42", nil)
+
+	t.Run("param func", func(t *testing.T) {
+		SetPathParamExtractor(PathParamFunc(func(r *http.Request, key string) string {
+			if key == "id" {
+				return "42"
+			}
+			return ""
+		}))
+
+		if got := PathParam(req, "id"); got != "42" {
+			t.Fatalf("expected %q, got %q", "42", got)
+		}
+		if got := PathParam(req, "missing"); got != "" {
+			t.Fatalf("expected empty value, got %q", got)
+		}
+	})
+
+	t.Run("vars func", func(t *testing.T) {
+		SetPathParamExtractor(VarsFunc(func(r *http.Request) map[string]string {
+			return map[string]string{"id": "42"}
+		}))
+
+		if got := PathParam(req, "id"); got != "42" {
+			t.Fatalf("expected %q, got %q", "42", got)
+		}
+	})
+}
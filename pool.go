@@ -0,0 +1,41 @@
+package httpwr
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"sync"
+)
+
+// WithBufferPool gives every request a scratch buffer from p, retrieved with BufferFrom.
+// The buffer is reset before the handler runs and put back into p after the handler returns,
+// so handlers must not keep a reference to it.
+func WithBufferPool(p *sync.Pool) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			buf, ok := p.Get().(*bytes.Buffer)
+			if !ok {
+				buf = new(bytes.Buffer)
+			}
+			buf.Reset()
+
+			defer func() {
+				buf.Reset()
+				p.Put(buf)
+			}()
+
+			ctx := context.WithValue(r.Context(), bufferKey, buf)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// BufferFrom returns the pooled buffer stored by WithBufferPool.
+// If there is none, a new buffer is returned.
+func BufferFrom(ctx context.Context) *bytes.Buffer {
+	if buf, ok := ctx.Value(bufferKey).(*bytes.Buffer); ok {
+		return buf
+	}
+
+	return new(bytes.Buffer)
+}
@@ -0,0 +1,47 @@
+package httpwr
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+func TestWithBufferPool(t *testing.T) {
+	p := &sync.Pool{New: func() any {
+		return bytes.NewBufferString("leftover")
+	}}
+
+	seen := map[*bytes.Buffer]bool{}
+	var last *bytes.Buffer
+	h := WithBufferPool(p)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		last = BufferFrom(r.Context())
+		if last.Len() != 0 {
+			t.Fatalf("expected reset buffer, got %q", last.String())
+		}
+		last.WriteString("scratch")
+		seen[last] = true
+	}))
+
+	requests := 10
+	for i := 0; i < requests; i++ {
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+
+		if last.Len() != 0 {
+			t.Fatalf("expected buffer to be reset after the handler, got %q", last.String())
+		}
+	}
+
+	// sync.Pool may drop items, so only assert the buffers were reused at least once.
+	if len(seen) == requests {
+		t.Fatalf("expected buffers to be returned to the pool and reused")
+	}
+}
+
+func TestBufferFromWithoutPool(t *testing.T) {
+	if BufferFrom(context.Background()) == nil {
+		t.Fatalf("expected a buffer, got nil")
+	}
+}
@@ -0,0 +1,71 @@
+// Package promwr provides a Prometheus metrics middleware for httpwr.
+package promwr
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/samuelsih/httpwr"
+)
+
+// Metrics records the number of requests as http_requests_total
+// and their latency as http_request_duration_seconds,
+// labelled by method, path and final status.
+//
+// The path label is computed by route, so you can map raw paths into route patterns
+// and avoid high-cardinality labels. If route is nil, the raw URL path is used,
+// which should only be done when the set of paths is bounded.
+func Metrics(reg prometheus.Registerer, route func(*http.Request) string) httpwr.Middleware {
+	if route == nil {
+		route = func(r *http.Request) string {
+			return r.URL.Path
+		}
+	}
+
+	labels := []string{"method", "path", "status"}
+
+	requests := register(reg, prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "http_requests_total",
+		Help: "Total number of HTTP requests.",
+	}, labels))
+
+	duration := register(reg, prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "http_request_duration_seconds",
+		Help:    "Latency of HTTP requests in seconds.",
+		Buckets: prometheus.DefBuckets,
+	}, labels))
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			rec := httpwr.NewStatusRecorder(w)
+
+			next.ServeHTTP(rec, r)
+
+			values := []string{r.Method, route(r), strconv.Itoa(rec.Status)}
+			requests.WithLabelValues(values...).Inc()
+			duration.WithLabelValues(values...).Observe(time.Since(start).Seconds())
+		})
+	}
+}
+
+// register registers c, or returns the already registered collector
+// so Metrics can be used more than once with the same registerer.
+func register[T prometheus.Collector](reg prometheus.Registerer, c T) T {
+	err := reg.Register(c)
+	if err == nil {
+		return c
+	}
+
+	var are prometheus.AlreadyRegisteredError
+	if errors.As(err, &are) {
+		if existing, ok := are.ExistingCollector.(T); ok {
+			return existing
+		}
+	}
+
+	panic(err)
+}
@@ -0,0 +1,70 @@
+package promwr
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/samuelsih/httpwr"
+)
+
+func TestMetrics(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	mw := Metrics(reg, func(r *http.Request) string {
+		return "/users/{id}"
+	})
+
+	h := mw(httpwr.F(func(w http.ResponseWriter, r *http.Request) error {
+		if r.URL.Query().Get("fail") != "" {
+			return httpwr.Errorf(http.StatusNotFound, "not found")
+		}
+		return httpwr.OK(w, http.StatusOK, httpwr.OKMsg)
+	}))
+
+	for _, target := range []string{"/users/1", "/users/2", "/users/3?fail=1"} {
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", target, nil))
+	}
+
+	families, err := reg.Gather()
+	if err != nil {
+		t.Fatalf("got error: %v", err)
+	}
+
+	got := map[string]float64{}
+	for _, mf := range families {
+		if mf.GetName() != "http_requests_total" {
+			continue
+		}
+		for _, m := range mf.GetMetric() {
+			labels := map[string]string{}
+			for _, l := range m.GetLabel() {
+				labels[l.GetName()] = l.GetValue()
+			}
+			if labels["path"] != "/users/{id}" || labels["method"] != "GET" {
+				t.Fatalf("unexpected labels: %v", labels)
+			}
+			got[labels["status"]] = m.GetCounter().GetValue()
+		}
+	}
+
+	if got["200"] != 2 {
+		t.Fatalf("expected 2 requests with status 200, got %v", got["200"])
+	}
+	if got["404"] != 1 {
+		t.Fatalf("expected 1 request with status 404, got %v", got["404"])
+	}
+}
+
+func TestMetricsRegisterTwice(t *testing.T) {
+	reg := prometheus.NewRegistry()
+
+	defer func() {
+		if r := recover(); r != nil {
+			t.Fatalf("expected no panic, got %v", r)
+		}
+	}()
+
+	Metrics(reg, nil)
+	Metrics(reg, nil)
+}
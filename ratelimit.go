@@ -0,0 +1,121 @@
+package httpwr
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// ErrTooManyRequests is sent when a client exceeds its rate limit.
+var ErrTooManyRequests = errors.New("too many requests")
+
+// RateLimitByPrincipal allows at most limit requests per window for every principal
+// returned by principalFrom, using the request context.
+// Anonymous requests, where principalFrom returns an empty string, are limited by client IP.
+// Requests over the limit are rejected with http.StatusTooManyRequests and a Retry-After header.
+func RateLimitByPrincipal(limit int, window time.Duration, principalFrom func(context.Context) string) func(http.Handler) http.Handler {
+	l := newWindowLimiter(limit, window)
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := "ip:" + clientIP(r)
+			if principal := principalFrom(r.Context()); principal != "" {
+				key = "principal:" + principal
+			}
+
+			if ok, retryAfter := l.allow(key); !ok {
+				tooManyRequests(w, retryAfter)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// tooManyRequests sends http.StatusTooManyRequests telling the client when to retry.
+func tooManyRequests(w http.ResponseWriter, retryAfter time.Duration) {
+	seconds := int(math.Ceil(retryAfter.Seconds()))
+	if seconds < 1 {
+		seconds = 1
+	}
+
+	w.Header().Set("Retry-After", strconv.Itoa(seconds))
+	DefaultErrorHandler(w, http.StatusTooManyRequests, ErrTooManyRequests)
+}
+
+// clientIP returns the IP of the client from the remote address of the request.
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+
+	return host
+}
+
+// windowLimiter is a fixed window rate limiter.
+type windowLimiter struct {
+	mu        sync.Mutex
+	limit     int
+	window    time.Duration
+	counters  map[string]*windowCounter
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+type windowCounter struct {
+	start time.Time
+	count int
+}
+
+func newWindowLimiter(limit int, window time.Duration) *windowLimiter {
+	return &windowLimiter{
+		limit:    limit,
+		window:   window,
+		counters: make(map[string]*windowCounter),
+		now:      time.Now,
+	}
+}
+
+// allow reports whether a request for key is allowed,
+// otherwise it returns how long until the window resets.
+func (l *windowLimiter) allow(key string) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.sweep(now)
+
+	c, ok := l.counters[key]
+	if !ok || now.Sub(c.start) >= l.window {
+		c = &windowCounter{start: now}
+		l.counters[key] = c
+	}
+
+	if c.count >= l.limit {
+		return false, c.start.Add(l.window).Sub(now)
+	}
+
+	c.count++
+	return true, 0
+}
+
+// sweep removes the expired counters once per window, so the map does not grow forever.
+func (l *windowLimiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < l.window {
+		return
+	}
+
+	for key, c := range l.counters {
+		if now.Sub(c.start) >= l.window {
+			delete(l.counters, key)
+		}
+	}
+	l.lastSweep = now
+}
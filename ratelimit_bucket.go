@@ -0,0 +1,95 @@
+package httpwr
+
+import (
+	"container/list"
+	"net/http"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// rateLimitMaxKeys is the maximum number of keys tracked by RateLimit.
+// The least recently seen keys are evicted first.
+const rateLimitMaxKeys = 10000
+
+// RateLimit allows rps requests per second with bursts of up to burst requests
+// for every key returned by keyFn. If keyFn is nil, requests are limited by client IP.
+// Requests over the limit are rejected with http.StatusTooManyRequests and a Retry-After header.
+//
+// At most 10000 keys are tracked, the least recently seen ones are forgotten first,
+// so the memory used does not grow with the number of clients.
+func RateLimit(rps float64, burst int, keyFn func(*http.Request) string) Middleware {
+	if keyFn == nil {
+		keyFn = clientIP
+	}
+
+	limiters := newLimiterCache(rate.Limit(rps), burst, rateLimitMaxKeys)
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			res := limiters.get(keyFn(r)).Reserve()
+			if !res.OK() {
+				tooManyRequests(w, time.Second)
+				return
+			}
+
+			if delay := res.Delay(); delay > 0 {
+				res.Cancel()
+				tooManyRequests(w, delay)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// limiterCache holds a rate.Limiter per key, evicting the least recently used
+// key when it is full.
+type limiterCache struct {
+	limit rate.Limit
+	burst int
+	max   int
+
+	mu    sync.Mutex
+	order *list.List
+	items map[string]*list.Element
+}
+
+type limiterEntry struct {
+	key     string
+	limiter *rate.Limiter
+}
+
+func newLimiterCache(limit rate.Limit, burst, max int) *limiterCache {
+	return &limiterCache{
+		limit: limit,
+		burst: burst,
+		max:   max,
+		order: list.New(),
+		items: make(map[string]*list.Element),
+	}
+}
+
+// get returns the limiter of key, creating it if needed.
+func (c *limiterCache) get(key string) *rate.Limiter {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.items[key]; ok {
+		c.order.MoveToFront(el)
+		return el.Value.(*limiterEntry).limiter
+	}
+
+	if c.order.Len() >= c.max {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.items, oldest.Value.(*limiterEntry).key)
+	}
+
+	entry := &limiterEntry{key: key, limiter: rate.NewLimiter(c.limit, c.burst)}
+	c.items[key] = c.order.PushFront(entry)
+
+	return entry.limiter
+}
@@ -0,0 +1,60 @@
+package httpwr
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRateLimit(t *testing.T) {
+	h := RateLimit(1, 3, func(r *http.Request) string {
+		return r.Header.Get("X-Api-Key")
+	})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	serve := func(key string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", "/", nil)
+		req.Header.Set("X-Api-Key", key)
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		return w
+	}
+
+	var limited int
+	for i := 0; i < 6; i++ {
+		w := serve("a")
+		if w.Code == http.StatusTooManyRequests {
+			limited++
+			if w.Header().Get("Retry-After") == "" {
+				t.Fatalf("expected Retry-After header")
+			}
+		}
+	}
+	if limited < 2 {
+		t.Fatalf("expected requests past the burst to be limited, got %d limited", limited)
+	}
+
+	if w := serve("b"); w.Code != http.StatusOK {
+		t.Fatalf("expected http status ok for another key, got %d", w.Code)
+	}
+}
+
+func TestLimiterCacheEvicts(t *testing.T) {
+	c := newLimiterCache(1, 1, 2)
+
+	a := c.get("a")
+	c.get("b")
+	c.get("a")
+	c.get("c")
+
+	if len(c.items) != 2 {
+		t.Fatalf("expected 2 keys, got %d", len(c.items))
+	}
+	if _, ok := c.items["b"]; ok {
+		t.Fatalf("expected the least recently used key to be evicted")
+	}
+	if c.get("a") != a {
+		t.Fatalf("expected the recently used key to be kept")
+	}
+}
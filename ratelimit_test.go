@@ -0,0 +1,74 @@
+package httpwr
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type principalKey struct{}
+
+func TestRateLimitByPrincipal(t *testing.T) {
+	mw := RateLimitByPrincipal(2, time.Minute, func(ctx context.Context) string {
+		principal, _ := ctx.Value(principalKey{}).(string)
+		return principal
+	})
+	h := mw(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	serve := func(principal string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", "/", nil)
+		if principal != "" {
+			req = req.WithContext(context.WithValue(req.Context(), principalKey{}, principal))
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		return w
+	}
+
+	for i := 0; i < 2; i++ {
+		if w := serve("alice"); w.Code != http.StatusOK {
+			t.Fatalf("expected http status ok, got %d", w.Code)
+		}
+	}
+
+	w := serve("alice")
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("expected http status %d, got %d", http.StatusTooManyRequests, w.Code)
+	}
+	if w.Header().Get("Retry-After") == "" {
+		t.Fatalf("expected Retry-After header")
+	}
+
+	for i := 0; i < 2; i++ {
+		if w := serve("bob"); w.Code != http.StatusOK {
+			t.Fatalf("expected http status ok for another principal, got %d", w.Code)
+		}
+	}
+
+	// anonymous requests are limited by IP, independently from the principals.
+	if w := serve(""); w.Code != http.StatusOK {
+		t.Fatalf("expected http status ok for anonymous request, got %d", w.Code)
+	}
+}
+
+func TestWindowLimiterReset(t *testing.T) {
+	now := time.Now()
+	l := newWindowLimiter(1, time.Second)
+	l.now = func() time.Time { return now }
+
+	if ok, _ := l.allow("key"); !ok {
+		t.Fatalf("expected first request to be allowed")
+	}
+	if ok, _ := l.allow("key"); ok {
+		t.Fatalf("expected second request to be limited")
+	}
+
+	now = now.Add(time.Second)
+	if ok, _ := l.allow("key"); !ok {
+		t.Fatalf("expected request to be allowed in a new window")
+	}
+}
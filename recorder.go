@@ -0,0 +1,134 @@
+package httpwr
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"net/http"
+)
+
+// StatusRecorder wraps a http.ResponseWriter and records
+// the status code and the number of bytes written to it.
+type StatusRecorder struct {
+	http.ResponseWriter
+
+	// Status is the status code written to the response.
+	// It is http.StatusOK if the handler never called WriteHeader.
+	Status int
+
+	// Bytes is the number of body bytes written to the response.
+	Bytes int64
+
+	wroteHeader bool
+	hijacked    bool
+
+	// defaults returns the data set by SetDefaultData for the request being served,
+	// set by the handlers returned by New, so OKWithData can merge it.
+	defaults func() M
+}
+
+// NewStatusRecorder wraps the given http.ResponseWriter.
+// If w is already a *StatusRecorder, it is returned as is.
+func NewStatusRecorder(w http.ResponseWriter) *StatusRecorder {
+	if rec, ok := w.(*StatusRecorder); ok {
+		return rec
+	}
+
+	return &StatusRecorder{
+		ResponseWriter: w,
+		Status:         http.StatusOK,
+	}
+}
+
+// WriteHeader records the status and writes it to the underlying writer.
+// Nothing is written once the connection was hijacked.
+func (rec *StatusRecorder) WriteHeader(status int) {
+	if rec.wroteHeader || rec.hijacked {
+		return
+	}
+
+	rec.wroteHeader = true
+	rec.Status = status
+	rec.ResponseWriter.WriteHeader(status)
+}
+
+// Write writes the data to the underlying writer and counts the bytes.
+// It returns http.ErrHijacked once the connection was hijacked.
+func (rec *StatusRecorder) Write(b []byte) (int, error) {
+	if rec.hijacked {
+		return 0, http.ErrHijacked
+	}
+
+	if !rec.wroteHeader {
+		rec.WriteHeader(http.StatusOK)
+	}
+
+	n, err := rec.ResponseWriter.Write(b)
+	rec.Bytes += int64(n)
+
+	return n, err
+}
+
+// Written reports whether the header has been written.
+func (rec *StatusRecorder) Written() bool {
+	return rec.wroteHeader
+}
+
+// Hijacked reports whether the connection was hijacked, like by a WebSocket upgrade.
+// The response is owned by the handler then, so nothing else must be written.
+func (rec *StatusRecorder) Hijacked() bool {
+	return rec.hijacked
+}
+
+// Flush implements http.Flusher if the underlying writer supports it.
+func (rec *StatusRecorder) Flush() {
+	if rec.hijacked {
+		return
+	}
+
+	if f, ok := rec.ResponseWriter.(http.Flusher); ok {
+		if !rec.wroteHeader {
+			rec.WriteHeader(http.StatusOK)
+		}
+		f.Flush()
+	}
+}
+
+// FlushError flushes the underlying writer like http.ResponseController does,
+// so it returns http.ErrNotSupported if the underlying writer can not be flushed,
+// which Flush can not report.
+func (rec *StatusRecorder) FlushError() error {
+	if rec.hijacked {
+		return http.ErrHijacked
+	}
+
+	if err := http.NewResponseController(rec.ResponseWriter).Flush(); err != nil {
+		return err
+	}
+
+	// flushing sends the header with the default status.
+	rec.wroteHeader = true
+
+	return nil
+}
+
+// Hijack implements http.Hijacker if the underlying writer supports it.
+func (rec *StatusRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := rec.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, fmt.Errorf("httpwr: %T does not implement http.Hijacker", rec.ResponseWriter)
+	}
+
+	conn, rw, err := h.Hijack()
+	if err == nil {
+		rec.hijacked = true
+	}
+
+	return conn, rw, err
+}
+
+// Unwrap returns the underlying http.ResponseWriter.
+// It is used by http.ResponseController.
+func (rec *StatusRecorder) Unwrap() http.ResponseWriter {
+	return rec.ResponseWriter
+}
@@ -0,0 +1,67 @@
+package httpwr
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"net/http"
+)
+
+// RequestIDHeader is the header carrying the request ID.
+const RequestIDHeader = "X-Request-Id"
+
+// RequestID is a middleware that stores the request ID in the request context
+// and echoes it back in the X-Request-Id response header.
+// The ID is taken from the incoming X-Request-Id header, or a new UUID is generated.
+func RequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(RequestIDHeader)
+		if id == "" {
+			id = newUUID()
+		}
+
+		w.Header().Set(RequestIDHeader, id)
+		next.ServeHTTP(w, r.WithContext(WithRequestID(r.Context(), id)))
+	})
+}
+
+// newUUID returns a random (version 4) UUID.
+func newUUID() string {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		panic(fmt.Sprintf("httpwr: can not generate request id: %v", err))
+	}
+
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
+
+// WithRequestID returns a copy of ctx carrying the given request ID.
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey, id)
+}
+
+// RequestIDFromContext returns the request ID stored in ctx,
+// or an empty string if there is none.
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey).(string)
+	return id
+}
+
+// PropagateRequestID returns a copy of the outbound request req
+// with the request ID from ctx in the X-Request-Id header,
+// so the ID can be correlated across services.
+// If ctx has no request ID, req is returned as is.
+func PropagateRequestID(ctx context.Context, req *http.Request) *http.Request {
+	id := RequestIDFromContext(ctx)
+	if id == "" {
+		return req
+	}
+
+	out := req.Clone(req.Context())
+	out.Header.Set(RequestIDHeader, id)
+
+	return out
+}
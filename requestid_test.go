@@ -0,0 +1,75 @@
+package httpwr
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+)
+
+func TestPropagateRequestID(t *testing.T) {
+	ctx := WithRequestID(context.Background(), "abc-123")
+	outbound := httptest.NewRequest("GET", "http://upstream/api", nil)
+
+	got := PropagateRequestID(ctx, outbound)
+
+	if id := got.Header.Get(RequestIDHeader); id != "abc-123" {
+		t.Fatalf("expected request id %q, got %q", "abc-123", id)
+	}
+	if outbound.Header.Get(RequestIDHeader) != "" {
+		t.Fatalf("expected the original request to be left untouched")
+	}
+}
+
+func TestPropagateRequestIDWithoutID(t *testing.T) {
+	outbound := httptest.NewRequest("GET", "http://upstream/api", nil)
+
+	got := PropagateRequestID(context.Background(), outbound)
+
+	if got != outbound {
+		t.Fatalf("expected the same request to be returned")
+	}
+	if id := got.Header.Get(RequestIDHeader); id != "" {
+		t.Fatalf("expected no request id, got %q", id)
+	}
+}
+
+func TestRequestIDIncoming(t *testing.T) {
+	var got string
+	h := RequestID(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = RequestIDFromContext(r.Context())
+	}))
+
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set(RequestIDHeader, "abc-123")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+
+	if got != "abc-123" {
+		t.Fatalf("expected request id %q in context, got %q", "abc-123", got)
+	}
+	if id := w.Header().Get(RequestIDHeader); id != "abc-123" {
+		t.Fatalf("expected request id %q echoed, got %q", "abc-123", id)
+	}
+}
+
+func TestRequestIDGenerated(t *testing.T) {
+	var got string
+	h := RequestID(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = RequestIDFromContext(r.Context())
+	}))
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+
+	if got == "" {
+		t.Fatalf("expected a generated request id in context")
+	}
+	if id := w.Header().Get(RequestIDHeader); id != got {
+		t.Fatalf("expected request id %q echoed, got %q", got, id)
+	}
+	if ok, _ := regexp.MatchString(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`, got); !ok {
+		t.Fatalf("expected a UUID v4, got %q", got)
+	}
+}
@@ -0,0 +1,338 @@
+package httpwr
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// OKAdaptive sends the lite payload when the client asks to reduce data usage
+// with the `Save-Data: on` header, and the full payload otherwise.
+// The payload is sent like OKWithData with http.StatusOK.
+func OKAdaptive(w http.ResponseWriter, r *http.Request, full, lite M) error {
+	w.Header().Add("Vary", "Save-Data")
+
+	if strings.EqualFold(strings.TrimSpace(r.Header.Get("Save-Data")), "on") {
+		return OKWithData(w, http.StatusOK, OKMsg, lite)
+	}
+
+	return OKWithData(w, http.StatusOK, OKMsg, full)
+}
+
+// OKEmpty sends the status with an empty array as data,
+// so list endpoints never send null for an empty collection.
+func OKEmpty(w http.ResponseWriter, status int) error {
+	type r struct {
+		Status int   `json:"status,omitempty"`
+		Data   []any `json:"data"`
+	}
+
+	_ = writeJSON(w, status, r{
+		Status: bodyStatus(status),
+		Data:   []any{},
+	})
+
+	return nil
+}
+
+// OKOrNoContent sends http.StatusNoContent without a body when data has no keys,
+// and data like OKWithData with http.StatusOK otherwise, for update endpoints.
+// Both a nil M and an empty M{} have no keys, so both are sent as http.StatusNoContent.
+func OKOrNoContent(w http.ResponseWriter, data M) error {
+	if len(data) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	}
+
+	return OKWithData(w, http.StatusOK, OKMsg, data)
+}
+
+// UnsupportedMediaType sends http.StatusUnsupportedMediaType with the list of supported media types,
+// and sets them in the Accept-Post header.
+func UnsupportedMediaType(w http.ResponseWriter, got string, supported ...string) error {
+	if len(supported) > 0 {
+		w.Header().Set("Accept-Post", strings.Join(supported, ", "))
+	}
+
+	if supported == nil {
+		supported = []string{}
+	}
+
+	type r struct {
+		Status    int      `json:"status,omitempty"`
+		Err       string   `json:"error"`
+		Supported []string `json:"supported"`
+	}
+
+	_ = writeJSON(w, http.StatusUnsupportedMediaType, r{
+		Status:    bodyStatus(http.StatusUnsupportedMediaType),
+		Err:       fmt.Sprintf("unsupported media type %q", got),
+		Supported: supported,
+	})
+
+	return nil
+}
+
+// MethodNotAllowed sends http.StatusMethodNotAllowed
+// with the allowed methods in the Allow header.
+func MethodNotAllowed(w http.ResponseWriter, allowed ...string) error {
+	w.Header().Set("Allow", strings.Join(allowed, ", "))
+
+	_ = writeJSON(w, http.StatusMethodNotAllowed, errorResponse{
+		Status: bodyStatus(http.StatusMethodNotAllowed),
+		Err:    "method not allowed",
+	})
+
+	return nil
+}
+
+// OKProjected converts v to JSON, keeping only the fields requested
+// in the `fields` query parameter, like `?fields=id,author.name`.
+// Nested fields are separated by a dot, and arrays are projected element by element.
+// If the parameter is empty, v is sent as is.
+func OKProjected(w http.ResponseWriter, r *http.Request, status int, v any) error {
+	fields := r.URL.Query().Get("fields")
+	if fields == "" {
+		return writeJSON(w, status, v)
+	}
+
+	bts, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(bts))
+	dec.UseNumber()
+
+	var doc any
+	if err := dec.Decode(&doc); err != nil {
+		return err
+	}
+
+	return writeJSON(w, status, project(doc, parseFields(fields)))
+}
+
+// fieldTree is a set of requested fields, a nil subtree means the whole field.
+type fieldTree map[string]fieldTree
+
+func parseFields(fields string) fieldTree {
+	tree := fieldTree{}
+	for _, path := range strings.Split(fields, ",") {
+		path = strings.TrimSpace(path)
+		if path == "" {
+			continue
+		}
+
+		node := tree
+		parts := strings.Split(path, ".")
+		for i, part := range parts {
+			sub, ok := node[part]
+			if ok && sub == nil {
+				// the whole field was already requested.
+				break
+			}
+			if i == len(parts)-1 {
+				node[part] = nil
+				break
+			}
+			if sub == nil {
+				sub = fieldTree{}
+				node[part] = sub
+			}
+			node = sub
+		}
+	}
+
+	return tree
+}
+
+func project(v any, tree fieldTree) any {
+	switch v := v.(type) {
+	case map[string]any:
+		out := make(map[string]any, len(tree))
+		for key, sub := range tree {
+			val, ok := v[key]
+			if !ok {
+				continue
+			}
+			if sub == nil {
+				out[key] = val
+			} else {
+				out[key] = project(val, sub)
+			}
+		}
+		return out
+	case []any:
+		out := make([]any, len(v))
+		for i, elem := range v {
+			out[i] = project(elem, tree)
+		}
+		return out
+	default:
+		return v
+	}
+}
+
+// JSONDownload sends v as a JSON file attachment named filename,
+// so browsers download it instead of displaying it.
+func JSONDownload(w http.ResponseWriter, filename string, v any) error {
+	disposition := mime.FormatMediaType("attachment", map[string]string{"filename": filename})
+	if disposition == "" {
+		return fmt.Errorf("httpwr: invalid attachment filename %q", filename)
+	}
+
+	w.Header().Set("Content-Disposition", disposition)
+
+	return writeJSON(w, http.StatusOK, v)
+}
+
+// jsonpCallback matches the callback names accepted by JSONP,
+// like `handle` or `app.handlers.users`.
+var jsonpCallback = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]*(\.[A-Za-z_$][A-Za-z0-9_$]*)*$`)
+
+// JSONP sends data wrapped in a call to the function named by the `callback`
+// query parameter, for legacy clients loading it with a script tag.
+// Without a callback, data is sent as plain JSON.
+// A callback that is not a safe JavaScript identifier is rejected
+// with http.StatusBadRequest.
+func JSONP(w http.ResponseWriter, r *http.Request, status int, data any) error {
+	callback := r.URL.Query().Get("callback")
+	if callback == "" {
+		return writeJSON(w, status, data)
+	}
+
+	if len(callback) > 128 || !jsonpCallback.MatchString(callback) {
+		return Errorf(http.StatusBadRequest, "invalid callback %q", callback)
+	}
+
+	buf := getJSONBuffer()
+	defer putJSONBuffer(buf)
+
+	if err := buf.encode(data); err != nil {
+		return err
+	}
+
+	// The comment prevents the response from being read as another content type,
+	// like a Flash file.
+	body := make([]byte, 0, len(callback)+buf.Len()+8)
+	body = append(body, "/**/"...)
+	body = append(body, callback...)
+	body = append(body, '(')
+	body = append(body, bytes.TrimRight(buf.Bytes(), "\n")...)
+	body = append(body, ");"...)
+
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+
+	return writeBody(w, status, "application/javascript", body)
+}
+
+// OKWithLinks sends data with the links to the related resources,
+// like `{"status":200,"data":{...},"_links":{"self":"/users/1","next":"/users/2"}}`,
+// for HATEOAS style APIs. The links are keyed by their relation.
+func OKWithLinks(w http.ResponseWriter, status int, data M, links map[string]string) error {
+	if data == nil {
+		data = M{}
+	}
+	if links == nil {
+		links = map[string]string{}
+	}
+
+	type r struct {
+		Status int               `json:"status,omitempty"`
+		Data   M                 `json:"data"`
+		Links  map[string]string `json:"_links"`
+	}
+
+	_ = writeJSON(w, status, r{
+		Status: bodyStatus(status),
+		Data:   data,
+		Links:  links,
+	})
+
+	return nil
+}
+
+// ErrMissingLocation is returned by CreatedAt when the location is empty.
+var ErrMissingLocation = errors.New("httpwr: missing location of the created resource")
+
+// CreatedAt sends data like OKWithData with http.StatusCreated,
+// and sets the Location header to the location of the created resource.
+// An Error with http.StatusInternalServerError wrapping ErrMissingLocation
+// is returned if location is empty, nothing is written in that case.
+func CreatedAt(w http.ResponseWriter, location string, data M) error {
+	if location == "" {
+		return Wrap(http.StatusInternalServerError, ErrMissingLocation)
+	}
+
+	w.Header().Set("Location", location)
+
+	return OKWithData(w, http.StatusCreated, CreatedMsg, data)
+}
+
+// Respond sends data in the format preferred by the client in the Accept header.
+// It sends msgpack, like Msgpack, when the client prefers `application/msgpack`
+// and an encoder was set with SetMsgpackEncoder, and JSON otherwise.
+func Respond(w http.ResponseWriter, r *http.Request, status int, data any) error {
+	addVary(w.Header(), "Accept")
+
+	if msgpackEncoder != nil && prefersMsgpack(r.Header.Get("Accept")) {
+		return Msgpack(w, status, data)
+	}
+
+	return writeJSON(w, status, data)
+}
+
+// prefersMsgpack reports whether the Accept header ranks msgpack above JSON.
+func prefersMsgpack(accept string) bool {
+	if accept == "" {
+		return false
+	}
+
+	return acceptQuality(accept, "application/msgpack") > acceptQuality(accept, "application/json")
+}
+
+// acceptQuality returns the quality given to mediaType by the Accept header,
+// using the most specific matching range.
+func acceptQuality(accept, mediaType string) float64 {
+	typ, _, _ := strings.Cut(mediaType, "/")
+
+	q, specificity := 0.0, -1
+	for _, part := range strings.Split(accept, ",") {
+		rng, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		rng = strings.ToLower(strings.TrimSpace(rng))
+
+		var s int
+		switch rng {
+		case mediaType:
+			s = 2
+		case typ + "/*":
+			s = 1
+		case "*/*":
+			s = 0
+		default:
+			continue
+		}
+		if s < specificity {
+			continue
+		}
+
+		rq := 1.0
+		for _, param := range strings.Split(params, ";") {
+			if v, ok := strings.CutPrefix(strings.TrimSpace(param), "q="); ok {
+				if parsed, err := strconv.ParseFloat(v, 64); err == nil {
+					rq = parsed
+				}
+			}
+		}
+
+		q, specificity = rq, s
+	}
+
+	return q
+}
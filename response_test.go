@@ -0,0 +1,343 @@
+package httpwr
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestOKAdaptive(t *testing.T) {
+	full := M{"description": "a very long description"}
+	lite := M{"summary": "short"}
+
+	tests := []struct {
+		name     string
+		saveData string
+		want     string
+		notWant  string
+	}{
+		{name: "save data", saveData: "on", want: "summary", notWant: "description"},
+		{name: "no save data", saveData: "", want: "description", notWant: "summary"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/adaptive", nil)
+			if tt.saveData != "" {
+				req.Header.Set("Save-Data", tt.saveData)
+			}
+			w := httptest.NewRecorder()
+			F(func(w http.ResponseWriter, r *http.Request) error {
+				return OKAdaptive(w, r, full, lite)
+			}).ServeHTTP(w, req)
+			resp := w.Result()
+
+			bts, err := io.ReadAll(resp.Body)
+			if err != nil {
+				t.Fatalf("got error: %v", err)
+			}
+			if !strings.Contains(string(bts), tt.want) {
+				t.Fatalf("%q does not contain %q", string(bts), tt.want)
+			}
+			if strings.Contains(string(bts), tt.notWant) {
+				t.Fatalf("%q should not contain %q", string(bts), tt.notWant)
+			}
+		})
+	}
+}
+
+func TestUnsupportedMediaType(t *testing.T) {
+	req := httptest.NewRequest("POST", "/upload", nil)
+	w := httptest.NewRecorder()
+	F(func(w http.ResponseWriter, r *http.Request) error {
+		return UnsupportedMediaType(w, "text/yaml", "application/json", "application/x-www-form-urlencoded")
+	}).ServeHTTP(w, req)
+	resp := w.Result()
+
+	if resp.StatusCode != http.StatusUnsupportedMediaType {
+		t.Fatalf("expected http status %d, got %d", http.StatusUnsupportedMediaType, resp.StatusCode)
+	}
+
+	want := "application/json, application/x-www-form-urlencoded"
+	if got := resp.Header.Get("Accept-Post"); got != want {
+		t.Fatalf("expected Accept-Post %q, got %q", want, got)
+	}
+
+	bts, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("got error: %v", err)
+	}
+	for _, s := range []string{"text/yaml", "application/json", "application/x-www-form-urlencoded"} {
+		if !strings.Contains(string(bts), s) {
+			t.Fatalf("%q does not contain %q", string(bts), s)
+		}
+	}
+}
+
+func TestOKProjected(t *testing.T) {
+	type author struct {
+		Name  string `json:"name"`
+		Email string `json:"email"`
+	}
+	type post struct {
+		ID     int    `json:"id"`
+		Title  string `json:"title"`
+		Body   string `json:"body"`
+		Author author `json:"author"`
+	}
+
+	posts := []post{
+		{ID: 1, Title: "first", Body: "long body", Author: author{Name: "sam", Email: "sam@example.com"}},
+		{ID: 2, Title: "second", Body: "long body", Author: author{Name: "ana", Email: "ana@example.com"}},
+	}
+
+	req := httptest.NewRequest("GET", "/posts?fields=id,author.name", nil)
+	w := httptest.NewRecorder()
+	F(func(w http.ResponseWriter, r *http.Request) error {
+		return OKProjected(w, r, http.StatusOK, posts)
+	}).ServeHTTP(w, req)
+
+	var got []M
+	if err := json.NewDecoder(w.Result().Body).Decode(&got); err != nil {
+		t.Fatalf("got error: %v", err)
+	}
+
+	want := []M{
+		{"id": float64(1), "author": map[string]any{"name": "sam"}},
+		{"id": float64(2), "author": map[string]any{"name": "ana"}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+}
+
+func TestOKProjectedWithoutFields(t *testing.T) {
+	req := httptest.NewRequest("GET", "/posts", nil)
+	w := httptest.NewRecorder()
+	F(func(w http.ResponseWriter, r *http.Request) error {
+		return OKProjected(w, r, http.StatusOK, M{"id": 1, "title": "first"})
+	}).ServeHTTP(w, req)
+
+	bts, err := io.ReadAll(w.Result().Body)
+	if err != nil {
+		t.Fatalf("got error: %v", err)
+	}
+	if !strings.Contains(string(bts), `"title":"first"`) {
+		t.Fatalf("%q does not contain %q", string(bts), `"title":"first"`)
+	}
+}
+
+func TestMethodNotAllowed(t *testing.T) {
+	req := httptest.NewRequest("DELETE", "/items", nil)
+	w := httptest.NewRecorder()
+	F(func(w http.ResponseWriter, r *http.Request) error {
+		return MethodNotAllowed(w, http.MethodGet, http.MethodPost)
+	}).ServeHTTP(w, req)
+	resp := w.Result()
+
+	if resp.StatusCode != http.StatusMethodNotAllowed {
+		t.Fatalf("expected http status %d, got %d", http.StatusMethodNotAllowed, resp.StatusCode)
+	}
+	if got := resp.Header.Get("Allow"); got != "GET, POST" {
+		t.Fatalf("expected Allow %q, got %q", "GET, POST", got)
+	}
+}
+
+func TestOKEmpty(t *testing.T) {
+	req := httptest.NewRequest("GET", "/items", nil)
+	w := httptest.NewRecorder()
+	F(func(w http.ResponseWriter, r *http.Request) error {
+		return OKEmpty(w, http.StatusOK)
+	}).ServeHTTP(w, req)
+	resp := w.Result()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected http status ok, got %d", resp.StatusCode)
+	}
+
+	bts, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("got error: %v", err)
+	}
+	if !strings.Contains(string(bts), `"data":[]`) {
+		t.Fatalf("%q does not contain %q", string(bts), `"data":[]`)
+	}
+}
+
+func TestJSONDownload(t *testing.T) {
+	tests := []struct {
+		name        string
+		filename    string
+		disposition string
+	}{
+		{name: "plain", filename: "export.json", disposition: `attachment; filename=export.json`},
+		{name: "quoted", filename: `my "data".json`, disposition: `attachment; filename="my \"data\".json"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/export", nil)
+			w := httptest.NewRecorder()
+			F(func(w http.ResponseWriter, r *http.Request) error {
+				return JSONDownload(w, tt.filename, M{"id": 1})
+			}).ServeHTTP(w, req)
+			resp := w.Result()
+
+			if got := resp.Header.Get("Content-Disposition"); got != tt.disposition {
+				t.Fatalf("expected Content-Disposition %q, got %q", tt.disposition, got)
+			}
+
+			bts, err := io.ReadAll(resp.Body)
+			if err != nil {
+				t.Fatalf("got error: %v", err)
+			}
+			if string(bts) != `{"id":1}`+"\n" {
+				t.Fatalf("unexpected body %q", string(bts))
+			}
+		})
+	}
+}
+
+func TestJSONP(t *testing.T) {
+	tests := []struct {
+		name        string
+		url         string
+		wantStatus  int
+		contentType string
+		body        string
+	}{
+		{
+			name:        "valid callback",
+			url:         "/users?callback=app.onUsers",
+			wantStatus:  http.StatusOK,
+			contentType: "application/javascript",
+			body:        `/**/app.onUsers({"id":1});`,
+		},
+		{
+			name:        "missing callback",
+			url:         "/users",
+			wantStatus:  http.StatusOK,
+			contentType: "application/json",
+			body:        `{"id":1}` + "\n",
+		},
+		{
+			name:        "invalid callback",
+			url:         "/users?callback=alert(1)//",
+			wantStatus:  http.StatusBadRequest,
+			contentType: "application/json",
+			body:        `{"status":400,"error":"invalid callback \"alert(1)//\""}` + "\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			F(func(w http.ResponseWriter, r *http.Request) error {
+				return JSONP(w, r, http.StatusOK, M{"id": 1})
+			}).ServeHTTP(w, httptest.NewRequest("GET", tt.url, nil))
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("expected http status %d, got %d", tt.wantStatus, w.Code)
+			}
+			if got := w.Header().Get("Content-Type"); got != tt.contentType {
+				t.Fatalf("expected Content-Type %q, got %q", tt.contentType, got)
+			}
+			if got := w.Body.String(); got != tt.body {
+				t.Fatalf("expected body %q, got %q", tt.body, got)
+			}
+		})
+	}
+}
+
+func TestOKWithLinks(t *testing.T) {
+	w := httptest.NewRecorder()
+	_ = OKWithLinks(w, http.StatusOK, M{"id": 1}, map[string]string{
+		"self": "/users/1",
+		"next": "/users/2",
+	})
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected http status ok, got %d", w.Code)
+	}
+
+	var body struct {
+		Status int               `json:"status"`
+		Data   M                 `json:"data"`
+		Links  map[string]string `json:"_links"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&body); err != nil {
+		t.Fatalf("got error: %v", err)
+	}
+
+	want := map[string]string{"self": "/users/1", "next": "/users/2"}
+	if !reflect.DeepEqual(body.Links, want) {
+		t.Fatalf("expected links %v, got %v", want, body.Links)
+	}
+	if !reflect.DeepEqual(body.Data, M{"id": float64(1)}) {
+		t.Fatalf("unexpected data %v", body.Data)
+	}
+}
+
+func TestCreatedAt(t *testing.T) {
+	w := httptest.NewRecorder()
+	if err := CreatedAt(w, "/users/42", M{"id": 42}); err != nil {
+		t.Fatalf("got error: %v", err)
+	}
+
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected http status %d, got %d", http.StatusCreated, w.Code)
+	}
+	if got := w.Header().Get("Location"); got != "/users/42" {
+		t.Fatalf("expected Location %q, got %q", "/users/42", got)
+	}
+	if want := `{"status":201,"msg":"Created","data":{"id":42}}` + "\n"; w.Body.String() != want {
+		t.Fatalf("expected body %q, got %q", want, w.Body.String())
+	}
+}
+
+func TestCreatedAtEmptyLocation(t *testing.T) {
+	w := httptest.NewRecorder()
+	err := CreatedAt(w, "", M{"id": 42})
+
+	if !errors.Is(err, ErrMissingLocation) {
+		t.Fatalf("expected ErrMissingLocation, got %v", err)
+	}
+	if !errors.Is(err, Error{Status: http.StatusInternalServerError}) {
+		t.Fatalf("expected an Error with http status %d, got %v", http.StatusInternalServerError, err)
+	}
+	if w.Header().Get("Location") != "" || w.Body.Len() != 0 {
+		t.Fatalf("expected nothing to be written")
+	}
+}
+
+func TestOKOrNoContent(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     M
+		wantCode int
+		wantBody string
+	}{
+		{name: "nil", data: nil, wantCode: http.StatusNoContent},
+		{name: "empty", data: M{}, wantCode: http.StatusNoContent},
+		{name: "populated", data: M{"id": 1}, wantCode: http.StatusOK, wantBody: `{"status":200,"msg":"OK","data":{"id":1}}` + "\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			if err := OKOrNoContent(w, tt.data); err != nil {
+				t.Fatalf("got error: %v", err)
+			}
+
+			if w.Code != tt.wantCode {
+				t.Fatalf("expected http status %d, got %d", tt.wantCode, w.Code)
+			}
+			if w.Body.String() != tt.wantBody {
+				t.Fatalf("expected body %q, got %q", tt.wantBody, w.Body.String())
+			}
+		})
+	}
+}
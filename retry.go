@@ -0,0 +1,58 @@
+package httpwr
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+)
+
+// RetryBudget calls fn until it succeeds, up to attempts times,
+// waiting backoff before the first retry and doubling it after every retry.
+// fn is always called at least once, even if attempts is less than 1.
+//
+// If the context deadline is exceeded, the last error is wrapped with http.StatusGatewayTimeout.
+// If the attempts are exhausted or the context is canceled,
+// the last error is wrapped with http.StatusBadGateway.
+func RetryBudget(ctx context.Context, attempts int, backoff time.Duration, fn func(context.Context) error) error {
+	if attempts < 1 {
+		attempts = 1
+	}
+
+	var err error
+	for i := 0; i < attempts; i++ {
+		if i > 0 {
+			timer := time.NewTimer(backoff)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return retryError(ctx, err)
+			case <-timer.C:
+			}
+			backoff *= 2
+		}
+
+		if err = fn(ctx); err == nil {
+			return nil
+		}
+
+		if ctx.Err() != nil {
+			return retryError(ctx, err)
+		}
+	}
+
+	return retryError(ctx, err)
+}
+
+// retryError wraps the last error of RetryBudget with the status matching why it stopped.
+func retryError(ctx context.Context, err error) error {
+	if err == nil {
+		err = ctx.Err()
+	}
+
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return Wrap(http.StatusGatewayTimeout, err)
+	}
+
+	return Wrap(http.StatusBadGateway, err)
+}
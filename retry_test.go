@@ -0,0 +1,79 @@
+package httpwr
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestRetryBudget(t *testing.T) {
+	errUpstream := errors.New("upstream unavailable")
+
+	t.Run("succeeds after failures", func(t *testing.T) {
+		calls := 0
+		err := RetryBudget(context.Background(), 3, time.Millisecond, func(ctx context.Context) error {
+			calls++
+			if calls < 3 {
+				return errUpstream
+			}
+			return nil
+		})
+
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if calls != 3 {
+			t.Fatalf("expected 3 calls, got %d", calls)
+		}
+	})
+
+	t.Run("budget exhausted", func(t *testing.T) {
+		calls := 0
+		err := RetryBudget(context.Background(), 2, time.Millisecond, func(ctx context.Context) error {
+			calls++
+			return errUpstream
+		})
+
+		var herr Error
+		if !errors.As(err, &herr) || herr.Status != http.StatusBadGateway {
+			t.Fatalf("expected bad gateway Error, got %v", err)
+		}
+		if !errors.Is(err, errUpstream) {
+			t.Fatalf("expected the last error to be wrapped, got %v", err)
+		}
+		if calls != 2 {
+			t.Fatalf("expected 2 calls, got %d", calls)
+		}
+	})
+
+	t.Run("no attempts", func(t *testing.T) {
+		calls := 0
+		err := RetryBudget(context.Background(), 0, time.Millisecond, func(ctx context.Context) error {
+			calls++
+			return errUpstream
+		})
+
+		if !errors.Is(err, errUpstream) {
+			t.Fatalf("expected the error of fn, got %v", err)
+		}
+		if calls != 1 {
+			t.Fatalf("expected 1 call, got %d", calls)
+		}
+	})
+
+	t.Run("deadline exceeded", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+
+		err := RetryBudget(ctx, 10, time.Hour, func(ctx context.Context) error {
+			return errUpstream
+		})
+
+		var herr Error
+		if !errors.As(err, &herr) || herr.Status != http.StatusGatewayTimeout {
+			t.Fatalf("expected gateway timeout Error, got %v", err)
+		}
+	})
+}
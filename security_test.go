@@ -0,0 +1,79 @@
+package httpwr
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestSecureHeaders(t *testing.T) {
+	serve := func(opts SecureOptions) http.Header {
+		h := SecureHeaders(opts)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+		return w.Header()
+	}
+
+	t.Run("defaults", func(t *testing.T) {
+		h := serve(DefaultSecureOptions())
+
+		want := map[string]string{
+			"X-Content-Type-Options": "nosniff",
+			"X-Frame-Options":        "DENY",
+			"Referrer-Policy":        "strict-origin-when-cross-origin",
+		}
+		for k, v := range want {
+			if got := h.Get(k); got != v {
+				t.Fatalf("expected %s %q, got %q", k, v, got)
+			}
+		}
+		if got := h.Get("Strict-Transport-Security"); got != "" {
+			t.Fatalf("expected no HSTS by default, got %q", got)
+		}
+	})
+
+	t.Run("disable one", func(t *testing.T) {
+		opts := DefaultSecureOptions()
+		opts.FrameOptions = ""
+		h := serve(opts)
+
+		if got := h.Get("X-Frame-Options"); got != "" {
+			t.Fatalf("expected no X-Frame-Options, got %q", got)
+		}
+		if got := h.Get("X-Content-Type-Options"); got != "nosniff" {
+			t.Fatalf("expected X-Content-Type-Options %q, got %q", "nosniff", got)
+		}
+	})
+
+	t.Run("csp and hsts", func(t *testing.T) {
+		opts := DefaultSecureOptions()
+		opts.ContentSecurityPolicy = "default-src 'self'"
+		opts.HSTSMaxAge = 365 * 24 * time.Hour
+		opts.HSTSIncludeSubdomains = true
+		h := serve(opts)
+
+		if got := h.Get("Content-Security-Policy"); got != "default-src 'self'" {
+			t.Fatalf("unexpected Content-Security-Policy %q", got)
+		}
+		if got := h.Get("Strict-Transport-Security"); got != "max-age=31536000; includeSubDomains" {
+			t.Fatalf("unexpected Strict-Transport-Security %q", got)
+		}
+	})
+}
+
+func TestPermissionsPolicy(t *testing.T) {
+	h := PermissionsPolicy(map[string]string{
+		"geolocation": "()",
+		"camera":      "(self)",
+		"microphone":  "",
+	})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+
+	want := "camera=(self), geolocation=(), microphone=()"
+	if got := w.Header().Get("Permissions-Policy"); got != want {
+		t.Fatalf("expected Permissions-Policy %q, got %q", want, got)
+	}
+}
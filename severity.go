@@ -0,0 +1,54 @@
+package httpwr
+
+import (
+	"errors"
+	"net/http"
+)
+
+// Severity is the level an error should be logged at.
+type Severity int
+
+// The severities, from the least to the most severe.
+const (
+	SeverityInfo Severity = iota + 1
+	SeverityWarn
+	SeverityError
+)
+
+// String returns the name of the severity, like "warn".
+func (s Severity) String() string {
+	switch s {
+	case SeverityInfo:
+		return "info"
+	case SeverityWarn:
+		return "warn"
+	case SeverityError:
+		return "error"
+	default:
+		return "unknown"
+	}
+}
+
+// SeverityOf returns the level err should be logged at, so a logger can route it.
+// The Severity of an Error is used if set, otherwise it is inferred from the status:
+// SeverityError for 5xx, SeverityWarn for 4xx and SeverityInfo for the rest.
+// Any other error is SeverityError, since it is rendered with http.StatusInternalServerError.
+func SeverityOf(err error) Severity {
+	var herr Error
+	if !errors.As(err, &herr) {
+		return SeverityError
+	}
+
+	if herr.Severity != 0 {
+		return herr.Severity
+	}
+
+	switch {
+	case herr.Status >= http.StatusInternalServerError:
+		return SeverityError
+	case herr.Status >= http.StatusBadRequest:
+		return SeverityWarn
+	default:
+		return SeverityInfo
+	}
+}
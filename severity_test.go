@@ -0,0 +1,38 @@
+package httpwr
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+)
+
+func TestSeverityOf(t *testing.T) {
+	errNotFound := errors.New("not found")
+
+	tests := []struct {
+		name string
+		err  error
+		want Severity
+	}{
+		{name: "client error", err: Wrap(http.StatusNotFound, errNotFound), want: SeverityWarn},
+		{name: "server error", err: Wrap(http.StatusBadGateway, errNotFound), want: SeverityError},
+		{name: "not an error status", err: Wrap(http.StatusAccepted, errNotFound), want: SeverityInfo},
+		{name: "plain error", err: errNotFound, want: SeverityError},
+		{name: "explicit override", err: WrapSeverity(http.StatusNotFound, SeverityInfo, errNotFound), want: SeverityInfo},
+		{name: "explicit escalation", err: WrapSeverity(http.StatusConflict, SeverityError, errNotFound), want: SeverityError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SeverityOf(tt.err); got != tt.want {
+				t.Fatalf("expected severity %s, got %s", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestWrapSeverityNil(t *testing.T) {
+	if err := WrapSeverity(http.StatusNotFound, SeverityInfo, nil); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+}
@@ -0,0 +1,110 @@
+package httpwr
+
+import (
+	"net/http"
+	"sync"
+	"time"
+)
+
+// StaticJSON returns a handler that serves the value returned by load as JSON,
+// like a config or a feature manifest.
+// The value is cached for ttl and loaded again on the first request after it expires,
+// while the other requests keep getting the expired value until the load is done.
+// If load fails, the last loaded value is served, or http.StatusInternalServerError
+// is sent if no value was ever loaded, and load is not called again for a second.
+func StaticJSON(load func() (any, error), ttl time.Duration) http.Handler {
+	return &staticJSON{
+		load: load,
+		ttl:  ttl,
+		now:  time.Now,
+	}
+}
+
+type staticJSON struct {
+	load func() (any, error)
+	ttl  time.Duration
+	now  func() time.Time
+
+	mu       sync.Mutex
+	value    any
+	loaded   bool
+	loadedAt time.Time
+	failedAt time.Time
+	// loading is closed when the load in flight is done, it is nil if there is none.
+	loading chan struct{}
+}
+
+// staticRetryInterval is how long StaticJSON waits before calling load again after it failed.
+const staticRetryInterval = time.Second
+
+func (s *staticJSON) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	v, ok := s.get()
+	if !ok {
+		DefaultErrorHandler(w, http.StatusInternalServerError, ErrInternalServerError)
+		return
+	}
+
+	_ = writeJSON(w, http.StatusOK, v)
+}
+
+// get returns the cached value, loading it again if it expired.
+// Only one request loads it at a time, the others get the expired value,
+// or wait for the load if there is none yet.
+func (s *staticJSON) get() (any, bool) {
+	s.mu.Lock()
+
+	now := s.now()
+	fresh := s.loaded && now.Sub(s.loadedAt) < s.ttl
+	retry := !s.failedAt.IsZero() && now.Sub(s.failedAt) < staticRetryInterval
+	if fresh || retry || (s.loading != nil && s.loaded) {
+		v, ok := s.value, s.loaded
+		s.mu.Unlock()
+		return v, ok
+	}
+
+	if s.loading != nil {
+		done := s.loading
+		s.mu.Unlock()
+		<-done
+
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		return s.value, s.loaded
+	}
+
+	done := make(chan struct{})
+	s.loading = done
+	s.mu.Unlock()
+
+	finished := false
+	defer func() {
+		// load panicked, release the waiting requests.
+		if !finished {
+			s.mu.Lock()
+			s.loading = nil
+			s.failedAt = now
+			close(done)
+			s.mu.Unlock()
+		}
+	}()
+
+	v, err := s.load()
+	finished = true
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.loading = nil
+	close(done)
+
+	if err != nil {
+		s.failedAt = now
+		return s.value, s.loaded
+	}
+
+	s.value = v
+	s.loaded = true
+	s.loadedAt = now
+	s.failedAt = time.Time{}
+
+	return v, true
+}
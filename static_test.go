@@ -0,0 +1,187 @@
+package httpwr
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestStaticJSON(t *testing.T) {
+	var loads int
+	now := time.Now()
+
+	h := StaticJSON(func() (any, error) {
+		loads++
+		return M{"version": loads}, nil
+	}, time.Minute)
+	h.(*staticJSON).now = func() time.Time { return now }
+
+	serve := func() string {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest("GET", "/config", nil))
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected http status ok, got %d", w.Code)
+		}
+		return w.Body.String()
+	}
+
+	if got := serve(); got != `{"version":1}`+"\n" {
+		t.Fatalf("unexpected body %q", got)
+	}
+
+	now = now.Add(30 * time.Second)
+	if got := serve(); got != `{"version":1}`+"\n" {
+		t.Fatalf("expected the cached value within the ttl, got %q", got)
+	}
+
+	now = now.Add(time.Minute)
+	if got := serve(); got != `{"version":2}`+"\n" {
+		t.Fatalf("expected the value to be reloaded after the ttl, got %q", got)
+	}
+	if loads != 2 {
+		t.Fatalf("expected 2 loads, got %d", loads)
+	}
+}
+
+func TestStaticJSONLoadError(t *testing.T) {
+	fail := true
+	loads := 0
+	now := time.Now()
+
+	h := StaticJSON(func() (any, error) {
+		loads++
+		if fail {
+			return nil, errors.New("config not found")
+		}
+		return M{"ok": true}, nil
+	}, time.Minute)
+	h.(*staticJSON).now = func() time.Time { return now }
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/config", nil))
+	if w.Code != http.StatusInternalServerError {
+		t.Fatalf("expected http status internal server error, got %d", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/config", nil))
+	if w.Code != http.StatusInternalServerError {
+		t.Fatalf("expected http status internal server error, got %d", w.Code)
+	}
+	if loads != 1 {
+		t.Fatalf("expected load not to be retried within the retry interval, got %d loads", loads)
+	}
+
+	fail = false
+	now = now.Add(staticRetryInterval)
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/config", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected http status ok, got %d", w.Code)
+	}
+
+	fail = true
+	now = now.Add(2 * time.Minute)
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/config", nil))
+	if w.Code != http.StatusOK || w.Body.String() != `{"ok":true}`+"\n" {
+		t.Fatalf("expected the stale value, got %d %q", w.Code, w.Body.String())
+	}
+}
+
+func TestStaticJSONConcurrentLoad(t *testing.T) {
+	var loads atomic.Int32
+	started := make(chan struct{}, 1)
+	release := make(chan struct{})
+	now := time.Now()
+
+	h := StaticJSON(func() (any, error) {
+		n := loads.Add(1)
+		if n > 1 {
+			started <- struct{}{}
+			<-release
+		}
+		return M{"version": n}, nil
+	}, time.Minute)
+	s := h.(*staticJSON)
+	var mu sync.Mutex
+	s.now = func() time.Time {
+		mu.Lock()
+		defer mu.Unlock()
+		return now
+	}
+
+	serve := func() string {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest("GET", "/config", nil))
+		if w.Code != http.StatusOK {
+			t.Errorf("expected http status ok, got %d", w.Code)
+		}
+		return w.Body.String()
+	}
+
+	serve()
+
+	mu.Lock()
+	now = now.Add(2 * time.Minute)
+	mu.Unlock()
+
+	reloaded := make(chan string)
+	go func() { reloaded <- serve() }()
+	<-started
+
+	if got := serve(); got != `{"version":1}`+"\n" {
+		t.Fatalf("expected the stale value while the load is in flight, got %q", got)
+	}
+
+	close(release)
+	if got := <-reloaded; got != `{"version":2}`+"\n" {
+		t.Fatalf("expected the reloaded value, got %q", got)
+	}
+	if got := serve(); got != `{"version":2}`+"\n" {
+		t.Fatalf("expected the reloaded value to be cached, got %q", got)
+	}
+	if n := loads.Load(); n != 2 {
+		t.Fatalf("expected 2 loads, got %d", n)
+	}
+}
+
+func TestStaticJSONWaitsForFirstLoad(t *testing.T) {
+	var loads atomic.Int32
+	release := make(chan struct{})
+
+	h := StaticJSON(func() (any, error) {
+		loads.Add(1)
+		<-release
+		return M{"ok": true}, nil
+	}, time.Minute)
+
+	var wg sync.WaitGroup
+	codes := make([]int, 5)
+	for i := range codes {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, httptest.NewRequest("GET", "/config", nil))
+			codes[i] = w.Code
+		}(i)
+	}
+
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	for _, code := range codes {
+		if code != http.StatusOK {
+			t.Fatalf("expected http status ok, got %d", code)
+		}
+	}
+	if n := loads.Load(); n != 1 {
+		t.Fatalf("expected 1 load, got %d", n)
+	}
+}
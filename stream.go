@@ -0,0 +1,132 @@
+package httpwr
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+)
+
+// streamFlushEvery is the number of items written between flushes when streaming.
+const streamFlushEvery = 32
+
+// StreamJSON writes the items received from the channel as a JSON array,
+// encoding each item as it arrives, until the channel is closed.
+// The response is flushed periodically, so the client receives the items progressively.
+// A closed empty channel produces `[]`.
+//
+// If an item can not be encoded, the stream is terminated without closing the array,
+// so the client can detect the truncation, and the error is returned.
+// The producer should stop sending, for example by watching the request context.
+func StreamJSON(w http.ResponseWriter, status int, items <-chan any) error {
+	w.Header().Set("Content-Type", jsonContentType)
+	w.WriteHeader(status)
+
+	flusher, _ := w.(http.Flusher)
+
+	if _, err := w.Write([]byte("[")); err != nil {
+		return err
+	}
+
+	n := 0
+	for item := range items {
+		bts, err := json.Marshal(item)
+		if err != nil {
+			return err
+		}
+
+		if n > 0 {
+			bts = append([]byte(","), bts...)
+		}
+		if _, err := w.Write(bts); err != nil {
+			return err
+		}
+
+		n++
+		if flusher != nil && n%streamFlushEvery == 0 {
+			flusher.Flush()
+		}
+	}
+
+	if _, err := w.Write([]byte("]\n")); err != nil {
+		return err
+	}
+	if flusher != nil {
+		flusher.Flush()
+	}
+
+	return nil
+}
+
+// StreamPaged writes the items of every page returned by pageFn as one JSON array
+// with http.StatusOK, flushing the response after each page.
+// The pages are fetched lazily starting from page 1, until pageFn reports
+// there are no more pages.
+//
+// The first page is fetched before anything is written, so an error there is
+// returned with the response untouched. An error on a later page terminates
+// the stream without closing the array, like StreamJSON.
+func StreamPaged(w http.ResponseWriter, pageFn func(page int) ([]any, bool, error)) error {
+	items, more, err := pageFn(1)
+	if err != nil {
+		return err
+	}
+
+	w.Header().Set("Content-Type", jsonContentType)
+	w.WriteHeader(http.StatusOK)
+
+	flusher, _ := w.(http.Flusher)
+
+	if _, err := w.Write([]byte("[")); err != nil {
+		return err
+	}
+
+	n := 0
+	for page := 1; ; page++ {
+		if page > 1 {
+			items, more, err = pageFn(page)
+			if err != nil {
+				return err
+			}
+		}
+
+		for _, item := range items {
+			bts, err := json.Marshal(item)
+			if err != nil {
+				return err
+			}
+
+			if n > 0 {
+				bts = append([]byte(","), bts...)
+			}
+			if _, err := w.Write(bts); err != nil {
+				return err
+			}
+			n++
+		}
+
+		if flusher != nil {
+			flusher.Flush()
+		}
+
+		if !more {
+			break
+		}
+	}
+
+	_, err = w.Write([]byte("]\n"))
+	return err
+}
+
+// Flush sends the data written so far to the client.
+// It returns ErrStreamingUnsupported if w, or the writers it wraps, can not be flushed,
+// as found by http.ResponseController.
+// The writers wrapping the response in middlewares, like a gzip writer,
+// must implement http.Flusher or FlushError and pass the call through, as StatusRecorder does.
+func Flush(w http.ResponseWriter) error {
+	err := http.NewResponseController(w).Flush()
+	if errors.Is(err, http.ErrNotSupported) {
+		return ErrStreamingUnsupported
+	}
+
+	return err
+}
@@ -0,0 +1,165 @@
+package httpwr
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestStreamJSON(t *testing.T) {
+	items := make(chan any)
+	go func() {
+		defer close(items)
+		for i := 0; i < 40; i++ {
+			items <- M{"i": i}
+		}
+	}()
+
+	w := httptest.NewRecorder()
+	if err := StreamJSON(w, http.StatusOK, items); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if !w.Flushed {
+		t.Fatalf("expected the response to be flushed")
+	}
+
+	var got []M
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+		t.Fatalf("expected valid JSON array, got %q: %v", w.Body.String(), err)
+	}
+	if len(got) != 40 {
+		t.Fatalf("expected 40 items, got %d", len(got))
+	}
+	if !reflect.DeepEqual(got[39], M{"i": float64(39)}) {
+		t.Fatalf("unexpected last item %v", got[39])
+	}
+}
+
+func TestStreamJSONEmpty(t *testing.T) {
+	items := make(chan any)
+	close(items)
+
+	w := httptest.NewRecorder()
+	if err := StreamJSON(w, http.StatusOK, items); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if got := strings.TrimSpace(w.Body.String()); got != "[]" {
+		t.Fatalf("expected %q, got %q", "[]", got)
+	}
+}
+
+func TestStreamJSONEncodeError(t *testing.T) {
+	items := make(chan any, 2)
+	items <- M{"ok": true}
+	items <- make(chan int)
+	close(items)
+
+	w := httptest.NewRecorder()
+	if err := StreamJSON(w, http.StatusOK, items); err == nil {
+		t.Fatalf("expected error, got none")
+	}
+	if strings.HasSuffix(strings.TrimSpace(w.Body.String()), "]") {
+		t.Fatalf("expected the array not to be closed, got %q", w.Body.String())
+	}
+}
+
+func TestStreamPaged(t *testing.T) {
+	var fetched []int
+	pageFn := func(page int) ([]any, bool, error) {
+		fetched = append(fetched, page)
+		switch page {
+		case 1:
+			return []any{M{"id": 1}, M{"id": 2}}, true, nil
+		default:
+			return []any{M{"id": 3}}, false, nil
+		}
+	}
+
+	w := httptest.NewRecorder()
+	if err := StreamPaged(w, pageFn); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if got := strings.TrimSpace(w.Body.String()); got != `[{"id":1},{"id":2},{"id":3}]` {
+		t.Fatalf("unexpected body %q", got)
+	}
+	if !reflect.DeepEqual(fetched, []int{1, 2}) {
+		t.Fatalf("expected pages [1 2] to be fetched, got %v", fetched)
+	}
+	if !w.Flushed {
+		t.Fatalf("expected the response to be flushed")
+	}
+}
+
+func TestStreamPagedFirstPageError(t *testing.T) {
+	w := httptest.NewRecorder()
+	err := StreamPaged(w, func(page int) ([]any, bool, error) {
+		return nil, false, errors.New("database is down")
+	})
+
+	if err == nil {
+		t.Fatalf("expected error, got none")
+	}
+	if w.Body.Len() != 0 {
+		t.Fatalf("expected nothing to be written, got %q", w.Body.String())
+	}
+}
+
+// flushCounter counts the calls to Flush.
+type flushCounter struct {
+	http.ResponseWriter
+	flushes int
+}
+
+func (f *flushCounter) Flush() {
+	f.flushes++
+}
+
+func TestFlush(t *testing.T) {
+	fc := &flushCounter{ResponseWriter: httptest.NewRecorder()}
+
+	if err := Flush(fc); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if err := Flush(NewStatusRecorder(fc)); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if fc.flushes != 2 {
+		t.Fatalf("expected 2 flushes, got %d", fc.flushes)
+	}
+
+	var w struct{ http.ResponseWriter }
+	if err := Flush(w); !errors.Is(err, ErrStreamingUnsupported) {
+		t.Fatalf("expected ErrStreamingUnsupported, got %v", err)
+	}
+}
+
+func TestFlushThroughF(t *testing.T) {
+	tests := []struct {
+		name string
+		w    http.ResponseWriter
+		want error
+	}{
+		{name: "flusher", w: httptest.NewRecorder()},
+		{name: "no flusher", w: struct{ http.ResponseWriter }{httptest.NewRecorder()}, want: ErrStreamingUnsupported},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got error
+			F(func(w http.ResponseWriter, r *http.Request) error {
+				got = Flush(w)
+				return nil
+			}).ServeHTTP(tt.w, httptest.NewRequest("GET", "/", nil))
+
+			if !errors.Is(got, tt.want) {
+				t.Fatalf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
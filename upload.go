@@ -0,0 +1,42 @@
+package httpwr
+
+import (
+	"errors"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+)
+
+// MaxUploadBytes is the maximum size of a multipart request body read by File.
+var MaxUploadBytes int64 = 32 << 20
+
+// File returns the file uploaded in the named field of a multipart/form-data request.
+// The form is parsed with MaxMultipartMemory bytes in memory, the rest is stored
+// on disk in temporary files.
+// A body larger than MaxUploadBytes is rejected with http.StatusRequestEntityTooLarge,
+// and a malformed form or a missing field with http.StatusBadRequest.
+// Close the returned file when done.
+func File(r *http.Request, field string) (multipart.File, *multipart.FileHeader, error) {
+	if r.MultipartForm == nil {
+		if MaxUploadBytes > 0 && r.Body != nil {
+			r.Body = http.MaxBytesReader(nil, r.Body, MaxUploadBytes)
+		}
+
+		if err := r.ParseMultipartForm(MaxMultipartMemory); err != nil {
+			if tooLarge := tooLargeError(err); tooLarge != nil {
+				return nil, nil, tooLarge
+			}
+			return nil, nil, Wrap(http.StatusBadRequest, err)
+		}
+	}
+
+	f, fh, err := r.FormFile(field)
+	if errors.Is(err, http.ErrMissingFile) {
+		return nil, nil, Errorf(http.StatusBadRequest, "missing file %q", field)
+	}
+	if err != nil {
+		return nil, nil, Wrap(http.StatusBadRequest, fmt.Errorf("file %q: %w", field, err))
+	}
+
+	return f, fh, nil
+}
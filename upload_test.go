@@ -0,0 +1,76 @@
+package httpwr
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func newUploadRequest(t *testing.T, field, filename, content string) *http.Request {
+	t.Helper()
+
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	fw, err := mw.CreateFormFile(field, filename)
+	if err != nil {
+		t.Fatalf("got error: %v", err)
+	}
+	_, _ = io.WriteString(fw, content)
+	_ = mw.Close()
+
+	req := httptest.NewRequest("POST", "/upload", &body)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+
+	return req
+}
+
+func TestFile(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		req := newUploadRequest(t, "avatar", "me.png", "fake png")
+
+		f, fh, err := File(req, "avatar")
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		defer f.Close()
+
+		if fh.Filename != "me.png" {
+			t.Fatalf("expected filename %q, got %q", "me.png", fh.Filename)
+		}
+		bts, _ := io.ReadAll(f)
+		if string(bts) != "fake png" {
+			t.Fatalf("unexpected content %q", string(bts))
+		}
+	})
+
+	t.Run("missing field", func(t *testing.T) {
+		req := newUploadRequest(t, "avatar", "me.png", "fake png")
+
+		_, _, err := File(req, "document")
+
+		var herr Error
+		if !errors.As(err, &herr) || herr.Status != http.StatusBadRequest {
+			t.Fatalf("expected bad request Error, got %v", err)
+		}
+	})
+
+	t.Run("too large", func(t *testing.T) {
+		prev := MaxUploadBytes
+		MaxUploadBytes = 1024
+		t.Cleanup(func() { MaxUploadBytes = prev })
+
+		req := newUploadRequest(t, "avatar", "me.png", strings.Repeat("a", 4096))
+
+		_, _, err := File(req, "avatar")
+
+		var herr Error
+		if !errors.As(err, &herr) || herr.Status != http.StatusRequestEntityTooLarge {
+			t.Fatalf("expected request entity too large Error, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,73 @@
+package httpwr
+
+import (
+	"errors"
+	"net/http"
+)
+
+// ErrValidation is wrapped by the ValidationError returned by ErrorCollector.Err.
+var ErrValidation = errors.New("validation failed")
+
+// ValidationError holds the messages of the invalid fields, keyed by field.
+// DefaultErrorHandler sends them in the fields key of the error.
+type ValidationError struct {
+	Fields map[string]string
+}
+
+// Error implements the error interface.
+func (e *ValidationError) Error() string {
+	return ErrValidation.Error()
+}
+
+// Unwrap returns ErrValidation, so errors.Is(err, ErrValidation) holds.
+func (e *ValidationError) Unwrap() error {
+	return ErrValidation
+}
+
+// ErrorCollector collects the validation errors of several fields,
+// so all of them can be returned at once instead of only the first one:
+//
+//	var errs httpwr.ErrorCollector
+//	if req.Name == "" {
+//		errs.Add("name", "is required")
+//	}
+//	if !strings.Contains(req.Email, "@") {
+//		errs.Add("email", "is not an email")
+//	}
+//	if err := errs.Err(); err != nil {
+//		return err
+//	}
+//
+// The zero value is ready to use.
+type ErrorCollector struct {
+	fields map[string]string
+}
+
+// Add adds the message of the invalid field.
+// The messages of the same field are joined with "; ".
+func (c *ErrorCollector) Add(field, msg string) {
+	if c.fields == nil {
+		c.fields = make(map[string]string)
+	}
+
+	if prev, ok := c.fields[field]; ok {
+		msg = prev + "; " + msg
+	}
+
+	c.fields[field] = msg
+}
+
+// Err returns nil if no field was added, or an Error with http.StatusUnprocessableEntity
+// wrapping a *ValidationError with the messages of the fields.
+func (c *ErrorCollector) Err() error {
+	if len(c.fields) == 0 {
+		return nil
+	}
+
+	fields := make(map[string]string, len(c.fields))
+	for field, msg := range c.fields {
+		fields[field] = msg
+	}
+
+	return Wrap(http.StatusUnprocessableEntity, &ValidationError{Fields: fields})
+}
@@ -0,0 +1,67 @@
+package httpwr
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestErrorCollectorEmpty(t *testing.T) {
+	var errs ErrorCollector
+	if err := errs.Err(); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+}
+
+func TestErrorCollector(t *testing.T) {
+	var errs ErrorCollector
+	errs.Add("name", "is required")
+	errs.Add("email", "is not an email")
+
+	err := errs.Err()
+
+	if !errors.Is(err, Error{Status: http.StatusUnprocessableEntity}) {
+		t.Fatalf("expected an Error with http status %d, got %v", http.StatusUnprocessableEntity, err)
+	}
+	if !errors.Is(err, ErrValidation) {
+		t.Fatalf("expected ErrValidation, got %v", err)
+	}
+
+	var verr *ValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("expected a ValidationError, got %v", err)
+	}
+
+	want := map[string]string{"name": "is required", "email": "is not an email"}
+	if !reflect.DeepEqual(verr.Fields, want) {
+		t.Fatalf("expected fields %v, got %v", want, verr.Fields)
+	}
+
+	w := httptest.NewRecorder()
+	HandlerFn(func(w http.ResponseWriter, r *http.Request) error {
+		return err
+	}).ServeHTTP(w, httptest.NewRequest("POST", "/users", nil))
+
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected http status %d, got %d", http.StatusUnprocessableEntity, w.Code)
+	}
+	if want := `{"status":422,"error":"validation failed","fields":{"email":"is not an email","name":"is required"}}` + "\n"; w.Body.String() != want {
+		t.Fatalf("expected body %q, got %q", want, w.Body.String())
+	}
+}
+
+func TestErrorCollectorSameField(t *testing.T) {
+	var errs ErrorCollector
+	errs.Add("password", "is too short")
+	errs.Add("password", "needs a digit")
+
+	var verr *ValidationError
+	if !errors.As(errs.Err(), &verr) {
+		t.Fatalf("expected a ValidationError")
+	}
+	if got := verr.Fields["password"]; got != "is too short; needs a digit" {
+		t.Fatalf("unexpected message %q", got)
+	}
+}
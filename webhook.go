@@ -0,0 +1,42 @@
+package httpwr
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// VerifyHMAC checks the HMAC-SHA256 signature of the request body against
+// the hex encoded signature in the named header, with an optional "sha256=" prefix.
+// The body is restored, so it can still be read by the handler.
+// A body larger than DefaultMaxBodyBytes is rejected with http.StatusRequestEntityTooLarge
+// before the signature is checked.
+// A missing or wrong signature is wrapped with http.StatusUnauthorized.
+func VerifyHMAC(r *http.Request, secret []byte, header string) error {
+	signature := strings.TrimPrefix(r.Header.Get(header), "sha256=")
+	if signature == "" {
+		return Wrap(http.StatusUnauthorized, fmt.Errorf("%w: missing %s header", ErrUnauthorized, header))
+	}
+
+	want, err := hex.DecodeString(signature)
+	if err != nil {
+		return Wrap(http.StatusUnauthorized, fmt.Errorf("%w: malformed signature", ErrUnauthorized))
+	}
+
+	body, err := readBody(r, DefaultMaxBodyBytes)
+	if err != nil {
+		return err
+	}
+
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(body)
+
+	if !hmac.Equal(mac.Sum(nil), want) {
+		return Wrap(http.StatusUnauthorized, fmt.Errorf("%w: invalid signature", ErrUnauthorized))
+	}
+
+	return nil
+}
@@ -0,0 +1,85 @@
+package httpwr
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestVerifyHMAC(t *testing.T) {
+	secret := []byte("s3cr3t")
+	body := `{"event":"paid"}`
+
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(body))
+	valid := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+
+	tests := []struct {
+		name       string
+		signature  string
+		wantStatus int
+	}{
+		{name: "valid", signature: valid, wantStatus: 0},
+		{name: "invalid", signature: "sha256=" + strings.Repeat("ab", 32), wantStatus: http.StatusUnauthorized},
+		{name: "missing", signature: "", wantStatus: http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/webhook", strings.NewReader(body))
+			if tt.signature != "" {
+				req.Header.Set("X-Hub-Signature-256", tt.signature)
+			}
+
+			err := VerifyHMAC(req, secret, "X-Hub-Signature-256")
+
+			if tt.wantStatus == 0 {
+				if err != nil {
+					t.Fatalf("expected no error, got %v", err)
+				}
+
+				bts, err := io.ReadAll(req.Body)
+				if err != nil {
+					t.Fatalf("got error: %v", err)
+				}
+				if string(bts) != body {
+					t.Fatalf("expected body to be preserved, got %q", string(bts))
+				}
+				return
+			}
+
+			var herr Error
+			if !errors.As(err, &herr) || herr.Status != tt.wantStatus {
+				t.Fatalf("expected Error with status %d, got %v", tt.wantStatus, err)
+			}
+			if !errors.Is(err, ErrUnauthorized) {
+				t.Fatalf("expected error to be ErrUnauthorized, got %v", err)
+			}
+		})
+	}
+}
+
+func TestVerifyHMACTooLarge(t *testing.T) {
+	prev := DefaultMaxBodyBytes
+	DefaultMaxBodyBytes = 8
+	t.Cleanup(func() { DefaultMaxBodyBytes = prev })
+
+	secret := []byte("s3cr3t")
+	body := `{"event":"paid"}`
+
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(body))
+
+	req := httptest.NewRequest("POST", "/webhook", strings.NewReader(body))
+	req.Header.Set("X-Hub-Signature-256", "sha256="+hex.EncodeToString(mac.Sum(nil)))
+
+	err := VerifyHMAC(req, secret, "X-Hub-Signature-256")
+	if !errors.Is(err, Error{Status: http.StatusRequestEntityTooLarge}) {
+		t.Fatalf("expected Error with status %d, got %v", http.StatusRequestEntityTooLarge, err)
+	}
+}